@@ -1,6 +1,9 @@
 package common
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 type BackendType string
 
@@ -31,3 +34,21 @@ func (c *Config) AutoCreateBucket() bool {
 func (c *Config) GetSecure() bool {
 	return strings.HasPrefix(c.Endpoint, HttpsPrefix)
 }
+
+// String hides the access keys, so that the config can be printed or logged safely.
+func (c Config) String() string {
+	return fmt.Sprintf("{Endpoint:%s AccessKeyID:%s AccessKeySecret:%s BucketName:%s CreateBucketIfNotExists:%t}",
+		c.Endpoint, redactSecret(c.AccessKeyID), redactSecret(c.AccessKeySecret), c.BucketName, c.CreateBucketIfNotExists)
+}
+
+// GoString hides the access keys from the %#v verb as well.
+func (c Config) GoString() string {
+	return "common.Config" + c.String()
+}
+
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "REDACTED"
+}
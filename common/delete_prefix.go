@@ -0,0 +1,84 @@
+package common
+
+import (
+	"strings"
+	"sync"
+)
+
+// DeletePrefix deletes every object under prefix, including its directory marker,
+// with concurrent DeleteObjects batches. A failed batch does not stop the others, its keys
+// are reported in the Failed of the result; an error is returned only if the prefix cannot
+// be listed.
+func DeletePrefix(storage Storage, provider BackendType, prefix string, options *DeletePrefixOptions) (*DeleteObjectsResult, ObjectStorageError) {
+	if options == nil {
+		options = &DeletePrefixOptions{}
+	}
+
+	if strings.Trim(prefix, "/") == "" && !options.AllowRootPrefix {
+		return nil, NewInvalidArgumentError(provider, "refusing to delete the whole bucket, prefix: "+prefix)
+	}
+
+	objectKeyPrefix := ToDirPrefix(prefix)
+	objects, se := storage.ListObjects(ListOptions{
+		ObjectKeyPrefix:    objectKeyPrefix,
+		Recursive:          true,
+		IncludeDirectories: true,
+	})
+	if se != nil {
+		return nil, se
+	}
+
+	objectKeys := make([]string, 0, len(objects)+1)
+	for _, obj := range objects {
+		if obj.IsObject() {
+			objectKeys = append(objectKeys, obj.Name)
+		}
+	}
+	if objectKeyPrefix != "" {
+		// ListObjects never returns the marker of the prefix itself
+		exist, se := storage.ObjectExist(objectKeyPrefix)
+		if se != nil {
+			return nil, se
+		}
+		if exist {
+			objectKeys = append(objectKeys, objectKeyPrefix)
+		}
+	}
+
+	if options.DryRun {
+		return &DeleteObjectsResult{Deleted: objectKeys}, nil
+	}
+
+	chunks := ChunkKeys(objectKeys, MaxDeleteObjectsBatch)
+	chunkCh := make(chan []string, len(chunks))
+	for _, chunk := range chunks {
+		chunkCh <- chunk
+	}
+	close(chunkCh)
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = &DeleteObjectsResult{}
+	)
+	for i := 0; i < options.GetConcurrentNum(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunkCh {
+				chunkResult, se := storage.DeleteObjects(chunk)
+				mu.Lock()
+				if se != nil {
+					for _, objectKey := range chunk {
+						result.AddFailed(objectKey, se)
+					}
+				}
+				result.Merge(chunkResult)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package common
+
+// MaxDeleteObjectsBatch 批量删除接口单次请求允许的最大对象数
+const MaxDeleteObjectsBatch = 1000
+
+// KeyError 单个对象操作失败的原因
+type KeyError struct {
+	Key string
+	Err ObjectStorageError
+}
+
+// DeleteObjectsResult 批量删除的逐个对象结果
+type DeleteObjectsResult struct {
+	Deleted []string
+	Failed  []KeyError
+}
+
+func (r *DeleteObjectsResult) HasErrors() bool {
+	return len(r.Failed) > 0
+}
+
+func (r *DeleteObjectsResult) AddDeleted(keys ...string) {
+	r.Deleted = append(r.Deleted, keys...)
+}
+
+func (r *DeleteObjectsResult) AddFailed(key string, err ObjectStorageError) {
+	r.Failed = append(r.Failed, KeyError{Key: key, Err: err})
+}
+
+// Merge appends the deleted and failed keys of other to r.
+func (r *DeleteObjectsResult) Merge(other *DeleteObjectsResult) {
+	if other == nil {
+		return
+	}
+	r.Deleted = append(r.Deleted, other.Deleted...)
+	r.Failed = append(r.Failed, other.Failed...)
+}
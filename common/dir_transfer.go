@@ -0,0 +1,165 @@
+package common
+
+import (
+	"strings"
+	"sync"
+)
+
+// DirTransferResult 目录复制、移动、上传或下载的汇总结果。
+// 复制和移动时键为源对象键，上传和下载时键为对象键。
+type DirTransferResult struct {
+	Copied     []string // CopyDir、MoveDir 成功复制的对象
+	Uploaded   []string // FPutDir 成功上传的对象
+	Downloaded []string // FGetDir 成功下载的对象
+	Skipped    []string // 目标已存在且未开启覆盖
+	Failed     []KeyError
+}
+
+func (r *DirTransferResult) HasErrors() bool {
+	return len(r.Failed) > 0
+}
+
+// CopyDir copies every object under srcDirPath to destDirPath with concurrent
+// server-side copies. Objects that fail to be copied are reported in the Failed of the result
+// without stopping the others; an error is returned only if srcDirPath cannot be listed or
+// does not exist.
+func CopyDir(storage Storage, provider BackendType, srcDirPath, destDirPath string, options *CopyDirOptions) (*DirTransferResult, ObjectStorageError) {
+	return copyDir(storage, provider, srcDirPath, destDirPath, options, false)
+}
+
+// copyDir copies the directory, and its directory markers too if includeMarkers is set, so
+// that the empty sub directories of a moved directory are not lost.
+func copyDir(storage Storage, provider BackendType, srcDirPath, destDirPath string, options *CopyDirOptions, includeMarkers bool) (*DirTransferResult, ObjectStorageError) {
+	if options == nil {
+		options = &CopyDirOptions{}
+	}
+
+	srcPrefix, destPrefix := ToDirPrefix(srcDirPath), ToDirPrefix(destDirPath)
+	if srcPrefix == "" || destPrefix == "" {
+		return nil, NewInvalidArgumentError(provider, "directory path must not be the bucket root")
+	}
+	if strings.HasPrefix(destPrefix, srcPrefix) || strings.HasPrefix(srcPrefix, destPrefix) {
+		return nil, NewInvalidArgumentError(provider, "directories must not contain each other: "+srcPrefix+", "+destPrefix)
+	}
+
+	listed, se := storage.ListObjects(ListOptions{
+		ObjectKeyPrefix:    srcPrefix,
+		Recursive:          true,
+		IncludeDirectories: includeMarkers,
+	})
+	if se != nil {
+		return nil, se
+	}
+	// virtual directories have no object to copy
+	objects := listed[:0]
+	for _, obj := range listed {
+		if obj.IsObject() {
+			objects = append(objects, obj)
+		}
+	}
+	if len(objects) == 0 || includeMarkers {
+		// the listing never contains the marker of srcPrefix itself
+		exist, se := storage.ObjectExist(srcPrefix)
+		if se != nil {
+			return nil, se
+		}
+		if !exist && len(objects) == 0 {
+			return nil, NewNoSuchDirectoryError(provider, srcDirPath)
+		}
+		if exist && includeMarkers {
+			objects = append(objects, ObjectInfo{IsDir: true, IsDirMarker: true, Name: srcPrefix})
+		}
+	}
+
+	objectCh := make(chan ObjectInfo, len(objects))
+	for _, obj := range objects {
+		objectCh <- obj
+	}
+	close(objectCh)
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = &DirTransferResult{}
+	)
+	for i := 0; i < options.GetConcurrentNum(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range objectCh {
+				destObjectKey := destPrefix + strings.TrimPrefix(obj.Name, srcPrefix)
+				var se ObjectStorageError
+				if obj.IsDirMarker {
+					// markers are recreated, CopyObject does not accept keys ending with "/"
+					se = storage.MkDir(destObjectKey)
+				} else {
+					se = storage.CopyObject(obj.Name, destObjectKey, &options.CopyOptions)
+				}
+				mu.Lock()
+				switch {
+				case se == nil:
+					result.Copied = append(result.Copied, obj.Name)
+				case se.GetCode() == ErrCodeObjectAlreadyExists:
+					result.Skipped = append(result.Skipped, obj.Name)
+				default:
+					result.Failed = append(result.Failed, KeyError{Key: obj.Name, Err: se})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// MoveDir copies the directory with overwrite, like MoveObject, and then deletes
+// the copied source objects in batches unless PreserveSource is set.
+func MoveDir(storage Storage, provider BackendType, srcDirPath, destDirPath string, options *MoveDirOptions) (*DirTransferResult, ObjectStorageError) {
+	if options == nil {
+		options = &MoveDirOptions{}
+	}
+
+	result, se := copyDir(storage, provider, srcDirPath, destDirPath, &CopyDirOptions{
+		CopyOptions:   CopyOptions{Overwrite: true},
+		ConcurrentNum: options.ConcurrentNum,
+	}, true)
+	if se != nil || options.PreserveSource {
+		return result, se
+	}
+
+	deleteResult, se := storage.DeleteObjects(result.Copied)
+	if se != nil {
+		return result, se
+	}
+	result.Failed = append(result.Failed, deleteResult.Failed...)
+
+	// only drop the remaining directory markers of the source once everything has been moved
+	if result.HasErrors() {
+		return result, nil
+	}
+	deleteResult, se = deleteDirMarkers(storage, ToDirPrefix(srcDirPath))
+	if se != nil {
+		return result, se
+	}
+	result.Failed = append(result.Failed, deleteResult.Failed...)
+	return result, nil
+}
+
+func deleteDirMarkers(storage Storage, prefix string) (*DeleteObjectsResult, ObjectStorageError) {
+	objects, se := storage.ListObjects(ListOptions{
+		ObjectKeyPrefix:    prefix,
+		Recursive:          true,
+		IncludeDirectories: true,
+	})
+	if se != nil {
+		return nil, se
+	}
+	markers := []string{prefix}
+	for _, obj := range objects {
+		if obj.IsDirMarker {
+			markers = append(markers, obj.Name)
+		}
+	}
+	return storage.DeleteObjects(markers)
+}
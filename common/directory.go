@@ -0,0 +1,195 @@
+package common
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Directories are modelled the same way on every backend:
+//   - a key ending with "/" is an explicit directory marker, whatever its size;
+//   - every prefix of a key up to a "/" is a virtual directory, whether a marker exists or not.
+// A listing returns each directory once, with IsDir set and its name ending with "/",
+// and never returns the marker of the listed prefix itself.
+
+// NewDirInfo returns the object info of a virtual directory.
+func NewDirInfo(dirPath string) ObjectInfo {
+	return ObjectInfo{
+		IsDir: true,
+		Name:  ToDirPrefix(dirPath),
+	}
+}
+
+// DirectoryFilter applies the directory model and ListOptions.Filter to the pages of one listing.
+type DirectoryFilter struct {
+	prefix       string
+	recursive    bool
+	include      bool
+	startAfter   string
+	objectFilter ObjectFilter
+	seen         map[string]bool
+}
+
+func NewDirectoryFilter(opt ListOptions) *DirectoryFilter {
+	return &DirectoryFilter{
+		prefix:       opt.GetPrefix(),
+		recursive:    opt.Recursive,
+		include:      opt.IncludeDirectories,
+		startAfter:   opt.StartAfter,
+		objectFilter: opt.Filter,
+		seen:         make(map[string]bool),
+	}
+}
+
+// FilterListPage applies the directory model and ListOptions.Filter to a single page of ListPage.
+// Virtual directories are not derived from the keys, they could repeat on the following pages.
+func FilterListPage(opt ListOptions, objects []ObjectInfo) []ObjectInfo {
+	f := &DirectoryFilter{
+		prefix:       opt.GetPagePrefix(),
+		include:      opt.IncludeDirectories,
+		startAfter:   opt.StartAfter,
+		objectFilter: opt.Filter,
+		seen:         make(map[string]bool),
+	}
+	return f.Apply(objects)
+}
+
+// Apply drops or de-duplicates the directories of a page and, for recursive
+// listings, adds the virtual directories implied by the keys. Files that do
+// not pass the object filter are dropped.
+func (f *DirectoryFilter) Apply(objects []ObjectInfo) []ObjectInfo {
+	ret := make([]ObjectInfo, 0, len(objects))
+	for _, obj := range objects {
+		if obj.Name == f.prefix || !strings.HasPrefix(obj.Name, f.prefix) || f.isBeforeStart(obj) {
+			continue
+		}
+		if f.include && f.recursive {
+			ret = f.appendParentDirs(ret, obj.Name)
+		}
+		if !obj.IsDir {
+			if f.objectFilter.Match(obj, strings.TrimPrefix(obj.Name, f.prefix)) {
+				ret = append(ret, obj)
+			}
+			continue
+		}
+		if f.include && !f.seen[obj.Name] {
+			f.seen[obj.Name] = true
+			ret = append(ret, obj)
+		}
+	}
+	return ret
+}
+
+// isBeforeStart reports whether obj is not after startAfter. A directory still
+// has children after startAfter when startAfter lies inside it.
+func (f *DirectoryFilter) isBeforeStart(obj ObjectInfo) bool {
+	if f.startAfter == "" || obj.Name > f.startAfter {
+		return false
+	}
+	return !obj.IsDir || !strings.HasPrefix(f.startAfter, obj.Name)
+}
+
+func (f *DirectoryFilter) appendParentDirs(objects []ObjectInfo, name string) []ObjectInfo {
+	rel := strings.TrimSuffix(strings.TrimPrefix(name, f.prefix), "/")
+	for i := strings.Index(rel, "/"); i >= 0; {
+		dir := f.prefix + rel[:i+1]
+		if !f.seen[dir] {
+			f.seen[dir] = true
+			objects = append(objects, NewDirInfo(dir))
+		}
+		next := strings.Index(rel[i+1:], "/")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return objects
+}
+
+// MkDir creates the directory marker of dirPath, an empty object whose key ends with a slash.
+// The bucket root is rejected as an invalid argument.
+func MkDir(storage Storage, provider BackendType, dirPath string) ObjectStorageError {
+	prefix := ToDirPrefix(dirPath)
+	if prefix == "" {
+		return NewInvalidArgumentError(provider, "directory path must not be the bucket root")
+	}
+	return storage.PutObject(prefix, bytes.NewReader(nil))
+}
+
+// IsDir reports whether dirPath has a directory marker or contains any object.
+// The bucket root is always a directory.
+func IsDir(storage Storage, dirPath string) (bool, ObjectStorageError) {
+	prefix := ToDirPrefix(dirPath)
+	if prefix == "" {
+		return true, nil
+	}
+	exist, se := storage.ObjectExist(prefix)
+	if se != nil || exist {
+		return exist, se
+	}
+	return hasChildren(storage, prefix)
+}
+
+// RemoveDir deletes the directory dirPath. Unless recursive is set, the directory
+// must be empty. A directory with neither a marker nor objects is reported as
+// NoSuchDirectory, and a partial recursive removal returns the first failed delete.
+func RemoveDir(storage Storage, provider BackendType, dirPath string, recursive bool) ObjectStorageError {
+	prefix := ToDirPrefix(dirPath)
+	if prefix == "" {
+		return NewInvalidArgumentError(provider, "directory path must not be the bucket root")
+	}
+
+	if recursive {
+		exist, se := IsDir(storage, prefix)
+		if se != nil {
+			return se
+		}
+		if !exist {
+			return NewNoSuchDirectoryError(provider, dirPath)
+		}
+		result, se := DeletePrefix(storage, provider, prefix, nil)
+		if se != nil {
+			return se
+		}
+		if result.HasErrors() {
+			return result.Failed[0].Err
+		}
+		return nil
+	}
+
+	notEmpty, se := hasChildren(storage, prefix)
+	if se != nil {
+		return se
+	}
+	if notEmpty {
+		return NewDirectoryNotEmptyError(provider, dirPath)
+	}
+	exist, se := storage.ObjectExist(prefix)
+	if se != nil {
+		return se
+	}
+	if !exist {
+		// an empty virtual directory does not exist
+		return NewNoSuchDirectoryError(provider, dirPath)
+	}
+	result, se := storage.DeleteObjects([]string{prefix})
+	if se != nil {
+		return se
+	}
+	if result.HasErrors() {
+		return result.Failed[0].Err
+	}
+	return nil
+}
+
+func hasChildren(storage Storage, prefix string) (bool, ObjectStorageError) {
+	found := false
+	se := storage.WalkObjects(ListOptions{
+		ObjectKeyPrefix:    prefix,
+		IncludeDirectories: true,
+		MaxKeys:            2, // the first key may be the marker of prefix
+	}, func(objects []ObjectInfo) bool {
+		found = true
+		return false
+	})
+	return found, se
+}
@@ -1,5 +1,12 @@
 package common
 
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+)
+
 type ErrorProcessor interface {
 	Match(e error) bool
 	Process(e error) ObjectStorageError
@@ -20,3 +27,92 @@ func (p *BaseErrorProcessor) ProcessNext(err error) ObjectStorageError {
 	}
 	return nil
 }
+
+// ErrorProcessorRegistry holds the error processors of a backend. Instead of linking them
+// with SetNext, it hands an error to the first processor that matches it, so the registered
+// processors are shared by concurrent calls without being modified.
+type ErrorProcessorRegistry struct {
+	mu         sync.RWMutex
+	custom     []ErrorProcessor
+	processors []ErrorProcessor
+}
+
+// NewErrorProcessorRegistry returns a registry with the built-in processors of a backend.
+func NewErrorProcessorRegistry(processors ...ErrorProcessor) *ErrorProcessorRegistry {
+	return &ErrorProcessorRegistry{
+		processors: processors,
+	}
+}
+
+// Register adds a custom processor. Custom processors are tried in the order they were
+// registered and before the built-in ones, so they can override them.
+func (r *ErrorProcessorRegistry) Register(processor ErrorProcessor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.custom = append(r.custom, processor)
+}
+
+// Process converts the error with the first matching processor, or returns nil if none matches.
+func (r *ErrorProcessorRegistry) Process(err error) ObjectStorageError {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, processors := range [][]ErrorProcessor{r.custom, r.processors} {
+		for _, processor := range processors {
+			if processor.Match(err) {
+				return processor.Process(err)
+			}
+		}
+	}
+	return nil
+}
+
+// NetworkErrorProcessor converts the errors of the network, unwrapped from the *url.Error
+// of the HTTP transport, in the same way for every backend: timeouts become
+// ErrCodeRequestTimeout, and unreachable services, such as refused connections and unknown
+// hosts, become ErrCodeBadGateway. Cancellations, TLS failures and malformed URLs are not
+// network errors and are left to the other processors.
+type NetworkErrorProcessor struct {
+	*BaseErrorProcessor
+	provider BackendType
+}
+
+func NewNetworkErrorProcessor(provider BackendType) *NetworkErrorProcessor {
+	return &NetworkErrorProcessor{
+		BaseErrorProcessor: &BaseErrorProcessor{},
+		provider:           provider,
+	}
+}
+
+func (p *NetworkErrorProcessor) Match(e error) bool {
+	_, ok := networkErrorCode(e)
+	return ok
+}
+
+func (p *NetworkErrorProcessor) Process(e error) ObjectStorageError {
+	code, ok := networkErrorCode(e)
+	if !ok {
+		return p.ProcessNext(e)
+	}
+	return NewStorageError(p.provider, code, e.Error(), e)
+}
+
+// networkErrorCode returns the code of e if it is a timeout, a DNS error or a *net.OpError.
+func networkErrorCode(e error) (ErrorCode, bool) {
+	if errors.Is(e, context.Canceled) {
+		return "", false
+	}
+	var netErr net.Error
+	if errors.Is(e, context.DeadlineExceeded) || errors.As(e, &netErr) && netErr.Timeout() {
+		return ErrCodeRequestTimeout, true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(e, &dnsErr) {
+		return ErrCodeBadGateway, true
+	}
+	var opErr *net.OpError
+	// crypto/tls reports the alerts of a failed handshake as "local error" and "remote error"
+	if errors.As(e, &opErr) && opErr.Op != "local error" && opErr.Op != "remote error" {
+		return ErrCodeBadGateway, true
+	}
+	return "", false
+}
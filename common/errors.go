@@ -3,6 +3,11 @@ package common
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
 )
 
 type ErrorCode = string
@@ -13,21 +18,92 @@ const (
 	ErrCodeBadGateway             ErrorCode = "BadGateway"
 	ErrCodeNoSuchFile             ErrorCode = "NoSuchFile"
 	ErrCodeNoSuchBucket           ErrorCode = "NoSuchBucket"
+	ErrCodeNoSuchVersion          ErrorCode = "NoSuchVersion"
 	ErrCodeAccessDenied           ErrorCode = "AccessDenied"
 	ErrCodeRequestTimeout         ErrorCode = "RequestTimeout"
+	ErrCodeSlowDown               ErrorCode = "SlowDown" // 请求被限流
 	ErrCodeNoSuchDirectory        ErrorCode = "NoSuchDirectory"
+	ErrCodeDirectoryNotEmpty      ErrorCode = "DirectoryNotEmpty"
 	ErrCodeInvalidObjectName      ErrorCode = "InvalidObjectName"
+	ErrCodeInvalidTag             ErrorCode = "InvalidTag"
+	ErrCodeInvalidArgument        ErrorCode = "InvalidArgument"
 	ErrCodeInvalidAccessKeyID     ErrorCode = "InvalidAccessKeyID"
 	ErrCodeObjectAlreadyExists    ErrorCode = "ObjectAlreadyExists"
 	ErrCodeBucketAlreadyExists    ErrorCode = "BucketAlreadyExists"
 	ErrCodeInvalidAccessKeySecret ErrorCode = "InvalidAccessKeySecret"
+	ErrCodeEntityTooLarge         ErrorCode = "EntityTooLarge"
+	ErrCodePreconditionFailed     ErrorCode = "PreconditionFailed"
+	ErrCodeInvalidRange           ErrorCode = "InvalidRange"
+	ErrCodeQuotaExceeded          ErrorCode = "QuotaExceeded"
+	ErrCodeInternalError          ErrorCode = "InternalError"
+	ErrCodeNotImplemented         ErrorCode = "NotImplemented"
+	ErrCodeInvalidBucketName      ErrorCode = "InvalidBucketName"
+	ErrCodeBucketNotEmpty         ErrorCode = "BucketNotEmpty"
+	ErrCodeServiceUnavailable     ErrorCode = "ServiceUnavailable"
+	ErrCodeMethodNotAllowed       ErrorCode = "MethodNotAllowed"
+	ErrCodeCircuitOpen            ErrorCode = "CircuitOpen" // 熔断器打开，调用未发送到后端
 )
 
+// Sentinel errors for errors.Is, each one matches a class of error codes.
+var (
+	// ErrNotFound matches every missing resource: NoSuchKey, NoSuchVersion, NoSuchBucket,
+	// and the local NoSuchFile and NoSuchDirectory. Use ErrBucketNotFound for buckets only.
+	ErrNotFound = errors.New("object storage: not found")
+	// ErrAlreadyExists matches ObjectAlreadyExists and BucketAlreadyExists.
+	ErrAlreadyExists = errors.New("object storage: already exists")
+	// ErrAccessDenied matches AccessDenied and the invalid credential codes.
+	ErrAccessDenied = errors.New("object storage: access denied")
+	// ErrBucketNotFound matches NoSuchBucket.
+	ErrBucketNotFound = errors.New("object storage: bucket not found")
+)
+
+var (
+	notFoundCodes = map[ErrorCode]bool{
+		ErrCodeNoSuchKey:       true,
+		ErrCodeNoSuchFile:      true,
+		ErrCodeNoSuchBucket:    true,
+		ErrCodeNoSuchVersion:   true,
+		ErrCodeNoSuchDirectory: true,
+	}
+	alreadyExistsCodes = map[ErrorCode]bool{
+		ErrCodeObjectAlreadyExists: true,
+		ErrCodeBucketAlreadyExists: true,
+	}
+	accessDeniedCodes = map[ErrorCode]bool{
+		ErrCodeAccessDenied:           true,
+		ErrCodeInvalidAccessKeyID:     true,
+		ErrCodeInvalidAccessKeySecret: true,
+	}
+	bucketNotFoundCodes = map[ErrorCode]bool{
+		ErrCodeNoSuchBucket: true,
+	}
+)
+
+// sentinelCodes returns the codes matched by target if it is a sentinel error. It compares
+// identities rather than looking target up in a map, which panics for uncomparable errors.
+func sentinelCodes(target error) (map[ErrorCode]bool, bool) {
+	switch target {
+	case ErrNotFound:
+		return notFoundCodes, true
+	case ErrAlreadyExists:
+		return alreadyExistsCodes, true
+	case ErrAccessDenied:
+		return accessDeniedCodes, true
+	case ErrBucketNotFound:
+		return bucketNotFoundCodes, true
+	}
+	return nil, false
+}
+
 type StorageError struct {
 	Provider BackendType
 	Code     string
 	Message  string
 	Native   error
+
+	StatusCode int    // HTTP 状态码，错误不是由服务端返回时为 0
+	RequestID  string // 服务端返回的请求 ID，向云厂商提交工单时需要提供
+	HostID     string // 服务端返回的主机 ID
 }
 
 func NewStorageError(provider BackendType, code string, message string, native error) *StorageError {
@@ -39,6 +115,14 @@ func NewStorageError(provider BackendType, code string, message string, native e
 	}
 }
 
+// WithResponse sets the details of the HTTP response the error was returned with.
+func (e *StorageError) WithResponse(statusCode int, requestID, hostID string) *StorageError {
+	e.StatusCode = statusCode
+	e.RequestID = requestID
+	e.HostID = hostID
+	return e
+}
+
 func (e *StorageError) GetCode() string {
 	return e.Code
 }
@@ -55,10 +139,94 @@ func (e *StorageError) GetNative() error {
 	return e.Native
 }
 
+func (e *StorageError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+func (e *StorageError) GetRequestID() string {
+	return e.RequestID
+}
+
+func (e *StorageError) GetHostID() string {
+	return e.HostID
+}
+
+// IsRetryable reports whether the error is a transient failure, such as a timeout,
+// throttling, a 5xx response or a broken connection, that may succeed when retried.
+func (e *StorageError) IsRetryable() bool {
+	if retryableCodes[e.Code] || retryableStatusCodes[e.StatusCode] {
+		return true
+	}
+	if e.Native == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(e.Native, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(e.Native, syscall.ECONNRESET) ||
+		errors.Is(e.Native, syscall.ECONNABORTED) ||
+		errors.Is(e.Native, syscall.EPIPE) ||
+		errors.Is(e.Native, io.ErrUnexpectedEOF)
+}
+
+// Unwrap returns the native error, so that errors.Is and errors.As look into it as well.
+func (e *StorageError) Unwrap() error {
+	return e.Native
+}
+
+// Is reports whether the error matches target, which is either one of the sentinel
+// errors such as ErrNotFound, or a *StorageError with the same code. A target without
+// a provider matches every provider.
+func (e *StorageError) Is(target error) bool {
+	if codes, ok := sentinelCodes(target); ok {
+		return codes[e.Code]
+	}
+	t, ok := target.(*StorageError)
+	if !ok || t == nil {
+		return false
+	}
+	return t.Code == e.Code && (t.Provider == "" || t.Provider == e.Provider)
+}
+
 func (e *StorageError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%sError: %s (code=%s, request_id=%s)", e.Provider, e.Message, e.Code, e.RequestID)
+	}
 	return fmt.Sprintf("%sError: %s (code=%s)", e.Provider, e.Message, e.Code)
 }
 
+var retryableCodes = map[ErrorCode]bool{
+	ErrCodeRequestTimeout:     true,
+	ErrCodeBadGateway:         true,
+	ErrCodeSlowDown:           true,
+	ErrCodeInternalError:      true,
+	ErrCodeServiceUnavailable: true,
+}
+
+var retryableStatusCodes = map[int]bool{
+	http.StatusRequestTimeout:      true,
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// ToError converts se to a plain error. It returns an untyped nil when se is nil, even
+// if se holds a nil *StorageError, which would otherwise make the error non-nil.
+//
+//	if err := common.ToError(storage.DeleteObject(key)); errors.Is(err, common.ErrNotFound) {
+func ToError(se ObjectStorageError) error {
+	if se == nil {
+		return nil
+	}
+	if e, ok := se.(*StorageError); ok && e == nil {
+		return nil
+	}
+	return se
+}
+
 func NewBucketNotFoundError(provider BackendType, bucketName string) ObjectStorageError {
 	message := "bucket not found: " + bucketName
 	native := errors.New(message)
@@ -77,6 +245,12 @@ func NewObjectNotFoundError(provider BackendType, objectKey string) ObjectStorag
 	return NewStorageError(provider, ErrCodeNoSuchKey, message, native)
 }
 
+func NewObjectVersionNotFoundError(provider BackendType, objectKey, versionID string) ObjectStorageError {
+	message := "object version not found: " + objectKey + "?versionId=" + versionID
+	native := errors.New(message)
+	return NewStorageError(provider, ErrCodeNoSuchVersion, message, native)
+}
+
 func NewObjectAlreadyExistError(provider BackendType, objectKey string) ObjectStorageError {
 	message := "object already exists: " + objectKey
 	native := errors.New(message)
@@ -100,3 +274,35 @@ func NewNoSuchDirectoryError(provider BackendType, dirPath string) ObjectStorage
 	native := errors.New(message)
 	return NewStorageError(provider, ErrCodeNoSuchDirectory, message, native)
 }
+
+func NewDirectoryNotEmptyError(provider BackendType, dirPath string) ObjectStorageError {
+	message := "directory not empty: " + dirPath
+	native := errors.New(message)
+	return NewStorageError(provider, ErrCodeDirectoryNotEmpty, message, native)
+}
+
+func NewCircuitOpenError(provider BackendType, operation string) ObjectStorageError {
+	message := "circuit breaker is open, rejected: " + operation
+	native := errors.New(message)
+	return NewStorageError(provider, ErrCodeCircuitOpen, message, native)
+}
+
+func NewInvalidTagError(provider BackendType, reason string) ObjectStorageError {
+	message := "invalid tag: " + reason
+	native := errors.New(message)
+	return NewStorageError(provider, ErrCodeInvalidTag, message, native)
+}
+
+func NewInvalidArgumentError(provider BackendType, reason string) ObjectStorageError {
+	message := "invalid argument: " + reason
+	native := errors.New(message)
+	return NewStorageError(provider, ErrCodeInvalidArgument, message, native)
+}
+
+// NewLocalFileError converts an error of the local file system.
+func NewLocalFileError(provider BackendType, filePath string, err error) ObjectStorageError {
+	if os.IsNotExist(err) {
+		return NewNoSuchFileError(provider, filePath)
+	}
+	return NewStorageError(provider, ErrCodeUnknown, err.Error(), err)
+}
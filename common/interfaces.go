@@ -7,6 +7,12 @@ type ObjectStorageError interface {
 	GetCode() string
 	GetMessage() string
 	GetNative() error
+	// GetStatusCode returns the HTTP status code, or 0 if the error was not returned by the service.
+	GetStatusCode() int
+	GetRequestID() string
+	GetHostID() string
+	// IsRetryable reports whether the error is a transient failure that may succeed when retried.
+	IsRetryable() bool
 	Error() string
 }
 
@@ -14,23 +20,89 @@ type StorageErrorConvert interface {
 	Convert(e error) ObjectStorageError
 }
 
+// ObjectPageFunc receives the objects of one listing page in key order.
+// Returning false stops the listing.
+type ObjectPageFunc func(objects []ObjectInfo) bool
+
 type Storage interface {
+	// GetLocation returns the service and bucket this storage operates on.
+	GetLocation() StorageLocation
+
 	CreateBucket(bucketName string) ObjectStorageError
 	BucketExists(bucketName string) (bool, ObjectStorageError)
 	EnsureBucket(bucketName string) ObjectStorageError
 
+	EnableVersioning(bucketName string) ObjectStorageError
+	SuspendVersioning(bucketName string) ObjectStorageError
+	GetVersioningStatus(bucketName string) (VersioningStatus, ObjectStorageError)
+
+	// GetBucketLifecycle returns an empty configuration when the bucket has no lifecycle rules.
+	GetBucketLifecycle(bucketName string) (*LifecycleConfiguration, ObjectStorageError)
+	// PutBucketLifecycle replaces all lifecycle rules, an empty configuration deletes them.
+	PutBucketLifecycle(bucketName string, config *LifecycleConfiguration) ObjectStorageError
+	DeleteBucketLifecycle(bucketName string) ObjectStorageError
+
 	ObjectExist(objectKey string) (bool, ObjectStorageError)
+	// StatObject returns the object info including its content type and user metadata.
+	StatObject(objectKey string) (*ObjectInfo, ObjectStorageError)
 	GetObject(objectKey string) (IObjectData, ObjectStorageError)
 	FGetObject(objectKey, localFilePath string) ObjectStorageError
 	FPutObject(localFilePath, objectKey string) ObjectStorageError
+	// FGetDir downloads every object under prefix into localDir, keeping the relative paths.
+	FGetDir(prefix, localDir string, options *LocalDirOptions) (*DirTransferResult, ObjectStorageError)
+	// FPutDir uploads every file under localDir to prefix, keeping the relative paths.
+	FPutDir(localDir, prefix string, options *LocalDirOptions) (*DirTransferResult, ObjectStorageError)
 	PutObject(objectKey string, reader io.Reader) ObjectStorageError
+	// PutObjectWithOptions uploads the object with extra options such as tags.
+	PutObjectWithOptions(objectKey string, reader io.Reader, options *PutOptions) ObjectStorageError
+	// FPutObjectWithOptions uploads the local file with extra options such as tags.
+	FPutObjectWithOptions(localFilePath, objectKey string, options *PutOptions) ObjectStorageError
 	DeleteObject(objectKey string) ObjectStorageError
+	// DeleteObjects deletes the objects with the multi-object delete API in batches of MaxDeleteObjectsBatch.
+	// Keys that do not exist are reported as deleted. Failures are reported per key in the result.
+	DeleteObjects(objectKeys []string) (*DeleteObjectsResult, ObjectStorageError)
+	// DeletePrefix recursively deletes every object under prefix. The prefix is treated as a directory.
+	DeletePrefix(prefix string, options *DeletePrefixOptions) (*DeleteObjectsResult, ObjectStorageError)
 	ListObjects(options ListOptions) ([]ObjectInfo, ObjectStorageError)
+	// WalkObjects lists the objects page by page as the backend returns them, without
+	// collecting them in memory. The objects are not sorted, use SortObjects if needed.
+	WalkObjects(options ListOptions, fn ObjectPageFunc) ObjectStorageError
+	// ListPage returns one page of at most options.MaxKeys objects starting at pageToken, and the
+	// token of the next page. An empty pageToken starts the listing, an empty next token ends it.
+	// ConcurrentNum and SortBy are ignored, and the page may be shorter when options.Filter is set.
+	ListPage(options ListOptions, pageToken string) ([]ObjectInfo, string, ObjectStorageError)
 	// CopyObject copies the object inside the bucket.
 	CopyObject(srcObjectKey, destObjectKey string, options *CopyOptions) ObjectStorageError
+	// CopyObjectFromBucket copies the object from another bucket of the same service into the bucket.
+	CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey string, options *CopyOptions) ObjectStorageError
 	// MoveObject moves the object inside the bucket.
 	MoveObject(srcObjectKey, destObjectKey string, options *MoveOptions) ObjectStorageError
 
-	// CopyDir(srcDirPath, destDirPath string) ObjectStorageError
-	// MoveDir(srcDirPath, destDirPath string) ObjectStorageError
+	GetObjectTags(objectKey string) (map[string]string, ObjectStorageError)
+	PutObjectTags(objectKey string, tags map[string]string) ObjectStorageError
+	DeleteObjectTags(objectKey string) ObjectStorageError
+
+	// ListObjectVersions lists all versions and delete markers under options.ObjectKeyPrefix.
+	// The prefix is used as is, so the versions of a single object can be listed by its key.
+	ListObjectVersions(options ListOptions) ([]ObjectInfo, ObjectStorageError)
+	GetObjectVersion(objectKey, versionID string) (IObjectData, ObjectStorageError)
+	DeleteObjectVersion(objectKey, versionID string) ObjectStorageError
+	// RestoreObjectVersion copies the given version over the object so that it becomes the current version.
+	RestoreObjectVersion(objectKey, versionID string) ObjectStorageError
+
+	// CopyDir copies every object under srcDirPath to destDirPath inside the bucket.
+	CopyDir(srcDirPath, destDirPath string, options *CopyDirOptions) (*DirTransferResult, ObjectStorageError)
+	// MoveDir moves every object under srcDirPath to destDirPath inside the bucket.
+	MoveDir(srcDirPath, destDirPath string, options *MoveDirOptions) (*DirTransferResult, ObjectStorageError)
+
+	// MkDir creates an empty directory by putting its directory marker.
+	MkDir(dirPath string) ObjectStorageError
+	// IsDir reports whether dirPath has a directory marker or contains any object.
+	IsDir(dirPath string) (bool, ObjectStorageError)
+	// RemoveDir deletes the directory and, if recursive is set, everything under it.
+	// Without recursive it fails with ErrCodeDirectoryNotEmpty if the directory has children.
+	RemoveDir(dirPath string, recursive bool) ObjectStorageError
+
+	// Sync synchronizes the local directory and the prefix in the direction of options.Direction.
+	Sync(localDir, prefix string, options *SyncOptions) (*SyncResult, ObjectStorageError)
 }
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LifecycleConfiguration 与后端无关的存储桶生命周期配置
+type LifecycleConfiguration struct {
+	Rules []LifecycleRule
+}
+
+type LifecycleRule struct {
+	ID      string // 规则 ID
+	Prefix  string // 规则作用的对象键前缀，为空时作用于整个存储桶
+	Enabled bool   // 是否启用该规则
+
+	Expiration                     *LifecycleExpiration            // 当前版本过期删除
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    // 历史版本过期删除
+	Transitions                    []LifecycleTransition           // 存储类型转换，MinIO 每条规则只支持一个转换
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload // 清理未完成的分片上传
+}
+
+type LifecycleExpiration struct {
+	// 最后修改时间之后多少天过期，与 Date 只能设置一个
+	Days int
+	// 在该日期之后过期，需为 UTC 零点。OSS 中对应 CreatedBeforeDate
+	Date time.Time
+}
+
+type NoncurrentVersionExpiration struct {
+	// 成为历史版本之后多少天过期
+	NoncurrentDays int
+}
+
+type LifecycleTransition struct {
+	// 最后修改时间之后多少天转换，与 Date 只能设置一个
+	Days int
+	// 在该日期之后转换，需为 UTC 零点。OSS 中对应 CreatedBeforeDate
+	Date time.Time
+	// 目标存储类型，例如 OSS 的 IA、Archive，或 MinIO 中配置的远程层级名称
+	StorageClass string
+}
+
+type AbortIncompleteMultipartUpload struct {
+	// 分片上传初始化之后多少天清理
+	DaysAfterInitiation int
+}
+
+func (c *LifecycleConfiguration) IsEmpty() bool {
+	return c == nil || len(c.Rules) == 0
+}
+
+// Validate checks the rules before they are translated to a backend configuration.
+func (c *LifecycleConfiguration) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *LifecycleRule) Validate() error {
+	if r.Expiration == nil && r.NoncurrentVersionExpiration == nil &&
+		len(r.Transitions) == 0 && r.AbortIncompleteMultipartUpload == nil {
+		return fmt.Errorf("lifecycle rule %q has no action", r.ID)
+	}
+	if r.Expiration != nil {
+		if err := validateDaysOrDate(r.Expiration.Days, r.Expiration.Date); err != nil {
+			return fmt.Errorf("lifecycle rule %q expiration: %w", r.ID, err)
+		}
+	}
+	if r.NoncurrentVersionExpiration != nil && r.NoncurrentVersionExpiration.NoncurrentDays <= 0 {
+		return fmt.Errorf("lifecycle rule %q noncurrent version expiration: days must be positive", r.ID)
+	}
+	for _, transition := range r.Transitions {
+		if err := validateDaysOrDate(transition.Days, transition.Date); err != nil {
+			return fmt.Errorf("lifecycle rule %q transition: %w", r.ID, err)
+		}
+		if transition.StorageClass == "" {
+			return fmt.Errorf("lifecycle rule %q transition: storage class is required", r.ID)
+		}
+	}
+	if r.AbortIncompleteMultipartUpload != nil && r.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+		return fmt.Errorf("lifecycle rule %q abort incomplete multipart upload: days must be positive", r.ID)
+	}
+	return nil
+}
+
+func validateDaysOrDate(days int, date time.Time) error {
+	if days < 0 {
+		return errors.New("days must not be negative")
+	}
+	if (days == 0) == date.IsZero() {
+		return errors.New("exactly one of days and date must be set")
+	}
+	return nil
+}
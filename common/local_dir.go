@@ -0,0 +1,109 @@
+package common
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// FPutDir uploads every regular file under localDir to prefix with concurrent
+// uploads, keeping the relative paths. Files that fail to be uploaded are reported in the
+// Failed of the result; an error is returned only if localDir cannot be read.
+func FPutDir(storage Storage, provider BackendType, localDir, prefix string, options *LocalDirOptions) (*DirTransferResult, ObjectStorageError) {
+	if options == nil {
+		options = &LocalDirOptions{}
+	}
+
+	localFiles, se := listLocalFiles(provider, localDir, true)
+	if se != nil {
+		return nil, se
+	}
+
+	objectKeyPrefix := ToDirPrefix(prefix)
+	tasks := make([]localDirTask, 0, len(localFiles))
+	for _, relPath := range sortedFilePaths(localFiles) {
+		localPath, _ := SafeLocalPath(localDir, relPath)
+		tasks = append(tasks, localDirTask{key: objectKeyPrefix + relPath, localPath: localPath})
+	}
+
+	result := &DirTransferResult{}
+	runLocalDirTasks(tasks, options.GetConcurrentNum(), func(task localDirTask) ObjectStorageError {
+		return storage.FPutObject(task.localPath, task.key)
+	}, &result.Uploaded, result)
+	return result, nil
+}
+
+// FGetDir downloads every object under prefix into localDir with concurrent
+// downloads, creating the local directories as needed. Keys that would escape
+// localDir are reported as failed, like the objects that fail to be downloaded; an error is
+// returned only if the prefix cannot be listed.
+func FGetDir(storage Storage, provider BackendType, prefix, localDir string, options *LocalDirOptions) (*DirTransferResult, ObjectStorageError) {
+	if options == nil {
+		options = &LocalDirOptions{}
+	}
+
+	objectKeyPrefix := ToDirPrefix(prefix)
+	objects, se := storage.ListObjects(ListOptions{
+		ObjectKeyPrefix: objectKeyPrefix,
+		Recursive:       true,
+	})
+	if se != nil {
+		return nil, se
+	}
+
+	result := &DirTransferResult{}
+	tasks := make([]localDirTask, 0, len(objects))
+	lastModified := make(map[string]time.Time, len(objects))
+	for _, obj := range objects {
+		localPath, ok := SafeLocalPath(localDir, strings.TrimPrefix(obj.Name, objectKeyPrefix))
+		if !ok {
+			result.Failed = append(result.Failed, KeyError{Key: obj.Name, Err: NewInvalidObjectNameError(provider, obj.Name)})
+			continue
+		}
+		tasks = append(tasks, localDirTask{key: obj.Name, localPath: localPath})
+		lastModified[obj.Name] = obj.LastModified
+	}
+
+	runLocalDirTasks(tasks, options.GetConcurrentNum(), func(task localDirTask) ObjectStorageError {
+		return downloadFile(storage, provider, task.key, task.localPath, lastModified[task.key])
+	}, &result.Downloaded, result)
+	return result, nil
+}
+
+// localDirTask transfers one file between the local directory and the bucket.
+type localDirTask struct {
+	key       string
+	localPath string
+}
+
+// runLocalDirTasks runs the tasks concurrently, appending the keys of the transferred
+// files to done and the failures to result.
+func runLocalDirTasks(tasks []localDirTask, concurrentNum int, run func(task localDirTask) ObjectStorageError, done *[]string, result *DirTransferResult) {
+	taskCh := make(chan localDirTask, len(tasks))
+	for _, task := range tasks {
+		taskCh <- task
+	}
+	close(taskCh)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for i := 0; i < concurrentNum; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskCh {
+				se := run(task)
+				mu.Lock()
+				if se != nil {
+					result.Failed = append(result.Failed, KeyError{Key: task.key, Err: se})
+				} else {
+					*done = append(*done, task.key)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
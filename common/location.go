@@ -0,0 +1,22 @@
+package common
+
+import "strings"
+
+// StorageLocation 标识一个 Storage 实际读写的服务和存储桶
+type StorageLocation struct {
+	Type       BackendType
+	Endpoint   string
+	BucketName string
+}
+
+// SameService reports whether both locations are served by the same endpoint,
+// which allows server-side copies between their buckets.
+func (l StorageLocation) SameService(other StorageLocation) bool {
+	return l.Type == other.Type && normalizeEndpoint(l.Endpoint) == normalizeEndpoint(other.Endpoint)
+}
+
+func normalizeEndpoint(endpoint string) string {
+	endpoint = strings.TrimPrefix(endpoint, HttpsPrefix)
+	endpoint = strings.TrimPrefix(endpoint, HttpPrefix)
+	return strings.ToLower(strings.TrimSuffix(endpoint, "/"))
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ObjectFilter 列举时逐页应用的过滤条件，只作用于文件，目录不受影响。
+// 所有条件同时满足时对象才会被返回，零值表示不过滤。
+type ObjectFilter struct {
+	// 相对于前缀的路径的 glob 模式（path.Match 语法），匹配完整相对路径或文件名即可。
+	// Include 不为空时只返回匹配的对象，Exclude 优先于 Include。
+	Include []string
+	Exclude []string
+
+	// 相对于前缀的路径的正则表达式，规则同 Include/Exclude
+	IncludeRegexp []*regexp.Regexp
+	ExcludeRegexp []*regexp.Regexp
+
+	// 对象键后缀，例如 ".csv"，满足任意一个即可
+	Suffixes []string
+
+	MinSize int64 // 最小大小（包含），0 表示不限制
+	MaxSize int64 // 最大大小（包含），0 表示不限制
+
+	ModifiedAfter  time.Time // 只返回在该时间之后修改的对象
+	ModifiedBefore time.Time // 只返回在该时间之前修改的对象
+}
+
+// IsEmpty reports whether the filter lets every object through.
+func (f *ObjectFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0 &&
+		len(f.IncludeRegexp) == 0 && len(f.ExcludeRegexp) == 0 &&
+		len(f.Suffixes) == 0 && f.MinSize == 0 && f.MaxSize == 0 &&
+		f.ModifiedAfter.IsZero() && f.ModifiedBefore.IsZero()
+}
+
+// Validate checks that the glob patterns are well formed.
+func (f *ObjectFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match reports whether the file obj, whose path relative to the listed prefix is relPath, passes the filter.
+func (f *ObjectFilter) Match(obj ObjectInfo, relPath string) bool {
+	if f.MinSize > 0 && obj.Size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && obj.Size > f.MaxSize {
+		return false
+	}
+	if !f.ModifiedAfter.IsZero() && !obj.LastModified.After(f.ModifiedAfter) {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && !obj.LastModified.Before(f.ModifiedBefore) {
+		return false
+	}
+	if len(f.Suffixes) > 0 && !hasAnySuffix(obj.Name, f.Suffixes) {
+		return false
+	}
+	if matchAnyPattern(f.Exclude, relPath) || matchAnyRegexp(f.ExcludeRegexp, relPath) {
+		return false
+	}
+	if len(f.Include) > 0 || len(f.IncludeRegexp) > 0 {
+		return matchAnyPattern(f.Include, relPath) || matchAnyRegexp(f.IncludeRegexp, relPath)
+	}
+	return true
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyRegexp(regexps []*regexp.Regexp, relPath string) bool {
+	name := path.Base(relPath)
+	for _, re := range regexps {
+		if re.MatchString(relPath) || re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
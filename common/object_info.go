@@ -12,20 +12,58 @@ type ObjectResult struct {
 
 type ObjectInfo struct {
 	IsDir        bool
+	IsDirMarker  bool // 条目本身是目录标记对象；为 false 的目录是虚拟目录，不对应任何对象
 	Name         string
 	Size         int64
 	LastModified time.Time
+
+	ETag string
+
+	// 以下字段仅在 StatObject 返回的结果中有效
+	ContentType string
+	Metadata    map[string]string // 用户自定义元数据，键为小写且不带 x-amz-meta-/x-oss-meta- 前缀
+
+	// 以下字段仅在 ListObjectVersions 返回的结果中有效
+	VersionID      string // 版本 ID
+	IsLatest       bool   // 是否为当前版本
+	IsDeleteMarker bool   // 是否为删除标记
 }
 
 func NewObjectInfo(name string, size int64, lastModified time.Time) ObjectInfo {
+	// 以 "/" 结尾的对象即为目录标记
+	isDirMarker := strings.HasSuffix(name, "/")
 	return ObjectInfo{
-		IsDir:        size == 0 && strings.HasSuffix(name, "/"),
+		IsDir:        isDirMarker,
+		IsDirMarker:  isDirMarker,
 		Name:         name,
 		Size:         size,
 		LastModified: lastModified,
 	}
 }
 
+func NewObjectVersionInfo(name string, size int64, lastModified time.Time, versionID string, isLatest, isDeleteMarker bool) ObjectInfo {
+	info := NewObjectInfo(name, size, lastModified)
+	info.VersionID = versionID
+	info.IsLatest = isLatest
+	info.IsDeleteMarker = isDeleteMarker
+	return info
+}
+
+// IsObject reports whether the entry exists as an object, i.e. it is a file or a directory marker.
+func (o *ObjectInfo) IsObject() bool {
+	return !o.IsDir || o.IsDirMarker
+}
+
+// IsVersionListable is IsListable for ListObjectVersions, which uses the prefix as is:
+// the versions of the object whose key equals the prefix are listed, only the marker
+// of the listed directory itself is not.
+func (o *ObjectInfo) IsVersionListable(objectKeyPrefix string, includeDirectories bool) bool {
+	if o.IsDir {
+		return includeDirectories && o.Name != objectKeyPrefix
+	}
+	return true
+}
+
 func (o *ObjectInfo) IsListable(objectKeyPrefix string, includeDirectories bool) bool {
 	if objectKeyPrefix == o.Name {
 		return false
@@ -49,5 +49,6 @@ func SortObjects(objects []ObjectInfo, sortBy SortBy, sortOrder SortOrder) {
 		}
 	}
 
-	sort.Slice(objects, less)
+	// 稳定排序，保证同名对象的多个版本保持后端返回的顺序
+	sort.SliceStable(objects, less)
 }
@@ -1,6 +1,9 @@
 package common
 
-import "strings"
+import (
+	"path"
+	"strings"
+)
 
 type Options struct {
 	Type   BackendType `json:"backend_type"`
@@ -14,6 +17,22 @@ type CopyOptions struct {
 	Overwrite bool
 }
 
+type PutOptions struct {
+	// 上传时为 Object 设置的标签，为空时不设置。
+	Tags map[string]string
+	// 用户自定义元数据，键不需要带 x-amz-meta-/x-oss-meta- 前缀。
+	Metadata    map[string]string
+	ContentType string
+	// 数据大小，小于等于 0 表示未知。已知大小时 MinIO 可以选择更合适的分片大小。
+	Size int64
+}
+
+type TransferOptions struct {
+	CopyOptions
+	// 是否禁用服务端复制。两端位于同一服务时默认先尝试服务端复制。
+	DisableServerSideCopy bool
+}
+
 type MoveOptions struct {
 	// 是否保留源 Object。默认为 false。
 	// 如果为 true，则保留源 Object；
@@ -21,21 +40,139 @@ type MoveOptions struct {
 	PreserveSource bool
 }
 
+type CopyDirOptions struct {
+	CopyOptions
+	ConcurrentNum int // 并发的复制请求数
+}
+
+func (opt *CopyDirOptions) GetConcurrentNum() int {
+	if opt.ConcurrentNum <= 0 {
+		return 1
+	}
+	return opt.ConcurrentNum
+}
+
+type MoveDirOptions struct {
+	MoveOptions
+	ConcurrentNum int // 并发的复制请求数
+}
+
+type LocalDirOptions struct {
+	ConcurrentNum int // 并发的传输数
+}
+
+func (opt *LocalDirOptions) GetConcurrentNum() int {
+	if opt.ConcurrentNum <= 0 {
+		return 1
+	}
+	return opt.ConcurrentNum
+}
+
+type SyncOptions struct {
+	Direction SyncDirection // 同步方向，默认为本地目录同步到对象前缀
+	Compare   SyncCompare   // 判断文件是否一致的方式，默认为 SyncCompareSize | SyncCompareModTime
+
+	// 是否删除目标端多余的文件。被 Include/Exclude 过滤掉的文件不会被删除。
+	DeleteExtraneous bool
+	// 上传时 prefix 为空（即整个存储桶）也允许删除多余的对象，默认拒绝，
+	// 以免误删存储桶中所有不在本地目录中的对象
+	AllowDeleteBucketRoot bool
+
+	// 相对路径的 glob 模式（path.Match 语法，使用 "/" 分隔），匹配完整相对路径或文件名即可。
+	// Include 不为空时只同步匹配的文件，Exclude 优先于 Include。
+	Include []string
+	Exclude []string
+
+	DryRun        bool // 只返回将要执行的操作，不实际执行
+	ConcurrentNum int  // 并发的传输数
+}
+
+func (opt *SyncOptions) GetCompare() SyncCompare {
+	if opt.Compare == 0 {
+		return SyncCompareSize | SyncCompareModTime
+	}
+	return opt.Compare
+}
+
+func (opt *SyncOptions) GetConcurrentNum() int {
+	if opt.ConcurrentNum <= 0 {
+		return 1
+	}
+	return opt.ConcurrentNum
+}
+
+// Validate checks that the Include and Exclude patterns are well formed.
+func (opt *SyncOptions) Validate() error {
+	for _, pattern := range append(append([]string{}, opt.Include...), opt.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsIncluded reports whether the file with the slash separated relative path takes part in the sync.
+func (opt *SyncOptions) IsIncluded(relPath string) bool {
+	if matchAnyPattern(opt.Exclude, relPath) {
+		return false
+	}
+	return len(opt.Include) == 0 || matchAnyPattern(opt.Include, relPath)
+}
+
+func matchAnyPattern(patterns []string, relPath string) bool {
+	name := path.Base(relPath)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type DeletePrefixOptions struct {
+	ConcurrentNum int  // 并发的批量删除请求数
+	DryRun        bool // 只返回将被删除的对象，不实际删除
+
+	// 是否允许空前缀或 "/"，即删除整个存储桶中的对象，默认为 false。
+	AllowRootPrefix bool
+}
+
+func (opt *DeletePrefixOptions) GetConcurrentNum() int {
+	if opt.ConcurrentNum <= 0 {
+		return 1
+	}
+	return opt.ConcurrentNum
+}
+
 type ListOptions struct {
-	ObjectKeyPrefix string // 对象键前缀
+	// 对象键前缀，作为目录处理，例如 "a/b" 等同于 "a/b/"。空字符串表示整个存储桶。
+	// ListObjectVersions 例外，前缀按原样使用。
+	ObjectKeyPrefix string
 
-	Recursive          bool // 是否递归处理子目录
-	IncludeDirectories bool // 是否包含目录 TODO: 返回结果有些差异，需要统一
+	Recursive bool // 是否递归处理子目录
+	// 是否包含目录。目录名以 "/" 结尾且每个目录只返回一次：非递归时为直接子目录，
+	// 递归时为前缀下的所有目录，包括目录标记和由对象键推导出的虚拟目录。
+	IncludeDirectories bool
 
-	ConcurrentNum int // 并发数，使用协程进行并发处理
-	MaxKeys       int // 每个批次请求的最大对象数
+	ConcurrentNum int // 并发数，递归列举时按子目录拆分前缀并发列举，目录越多加速越明显
+	MaxKeys       int // 每个批次请求的最大对象数，也是 ListPage 每页的最大对象数
+
+	StartAfter string // 从该键之后开始列举（不包含该键）
+	// 自定义分隔符，仅 ListPage 使用，为空时使用 "/"。设置后前缀按原样使用，不再作为目录处理
+	Delimiter string
 
 	SortBy    SortBy    // 排序方式，可以是 name、size 或 last_modified
 	SortOrder SortOrder // 排序顺序，可以是 asc（升序）或 desc（降序）
+
+	Filter ObjectFilter // 列举过程中逐页应用的过滤条件
 }
 
 func (opt *ListOptions) GetPrefix() string {
-	if !strings.HasSuffix(opt.ObjectKeyPrefix, "/") {
+	// 空前缀表示整个存储桶
+	if opt.ObjectKeyPrefix != "" && !strings.HasSuffix(opt.ObjectKeyPrefix, "/") {
 		return opt.ObjectKeyPrefix + "/"
 	}
 	return opt.ObjectKeyPrefix
@@ -55,6 +192,25 @@ func (opt *ListOptions) GetDelimiter() string {
 	return "/"
 }
 
+// GetPagePrefix returns the prefix used by ListPage.
+func (opt *ListOptions) GetPagePrefix() string {
+	if opt.Delimiter != "" {
+		return opt.ObjectKeyPrefix
+	}
+	return opt.GetPrefix()
+}
+
+// GetPageDelimiter returns the delimiter used by ListPage.
+func (opt *ListOptions) GetPageDelimiter() string {
+	if opt.Recursive {
+		return ""
+	}
+	if opt.Delimiter != "" {
+		return opt.Delimiter
+	}
+	return "/"
+}
+
 func (opt *ListOptions) GetConcurrentNum() int {
 	if opt.ConcurrentNum <= 0 {
 		return 1
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// pageToken is the content of the opaque ListPage token. It wraps the continuation
+// token of the backend, so that a token cannot be used with another backend.
+type pageToken struct {
+	Provider BackendType `json:"p"`
+	Token    string      `json:"t"`
+}
+
+// EncodePageToken wraps the continuation token of the backend into a ListPage token.
+// An empty continuation token ends the listing and is encoded as an empty token.
+func EncodePageToken(provider BackendType, continuationToken string) string {
+	if continuationToken == "" {
+		return ""
+	}
+	data, _ := json.Marshal(pageToken{Provider: provider, Token: continuationToken})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePageToken returns the continuation token of the backend wrapped in a ListPage token.
+func DecodePageToken(provider BackendType, token string) (string, ObjectStorageError) {
+	if token == "" {
+		return "", nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", NewInvalidArgumentError(provider, "malformed page token")
+	}
+	var t pageToken
+	if err := json.Unmarshal(data, &t); err != nil || t.Token == "" {
+		return "", NewInvalidArgumentError(provider, "malformed page token")
+	}
+	if t.Provider != provider {
+		return "", NewInvalidArgumentError(provider, "page token was issued by "+string(t.Provider))
+	}
+	return t.Token, nil
+}
@@ -0,0 +1,73 @@
+package common
+
+import "sync"
+
+// ListLevelFunc lists all pages of the direct children of prefix with the "/" delimiter.
+// It returns the objects and the common prefixes, i.e. the sub directories.
+type ListLevelFunc func(prefix string) (objects []ObjectInfo, prefixes []string, se ObjectStorageError)
+
+// ParallelListObjects recursively lists prefix by fanning out over the common prefixes,
+// so that every worker lists a disjoint part of the key space. The result contains every
+// object exactly once, like a flat recursive listing, in key order. The directory model
+// of DirectoryFilter is applied to the result.
+func ParallelListObjects(prefix string, opt ListOptions, listLevel ListLevelFunc) ([]ObjectInfo, ObjectStorageError) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		cond     = sync.NewCond(&mu)
+		queue    = []string{prefix}
+		pending  = 1 // prefixes queued or being listed
+		seen     = make(map[string]bool)
+		objects  []ObjectInfo
+		firstErr ObjectStorageError
+	)
+
+	worker := func() {
+		defer wg.Done()
+		for {
+			mu.Lock()
+			for len(queue) == 0 && pending > 0 && firstErr == nil {
+				cond.Wait()
+			}
+			if pending == 0 || firstErr != nil {
+				mu.Unlock()
+				return
+			}
+			current := queue[0]
+			queue = queue[1:]
+			mu.Unlock()
+
+			levelObjects, prefixes, se := listLevel(current)
+
+			mu.Lock()
+			if se != nil && firstErr == nil {
+				firstErr = se
+			}
+			for _, obj := range levelObjects {
+				if seen[obj.Name] {
+					continue
+				}
+				seen[obj.Name] = true
+				objects = append(objects, obj)
+			}
+			queue = append(queue, prefixes...)
+			pending += len(prefixes) - 1
+			cond.Broadcast()
+			mu.Unlock()
+		}
+	}
+
+	for i := 0; i < opt.GetConcurrentNum(); i++ {
+		wg.Add(1)
+		go worker()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// the workers finish in any order, markers must be seen before the keys below them
+	SortObjects(objects, SortByName, Ascending)
+	return NewDirectoryFilter(opt).Apply(objects), nil
+}
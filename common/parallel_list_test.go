@@ -0,0 +1,91 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeLevels builds a tree of depth levels with fanout sub directories and files per
+// directory. Every directory has a marker, which is also reported by the level of its
+// parent, so that the listing has to de-duplicate it. It returns the number of distinct keys.
+func fakeLevels(depth, fanout int, latency time.Duration) (ListLevelFunc, int) {
+	levels := make(map[string][2][]string)
+	keys := 0
+	var build func(prefix string, depth int)
+	build = func(prefix string, depth int) {
+		var objects, prefixes []string
+		if prefix != "" {
+			objects = append(objects, prefix)
+			keys++
+		}
+		for i := 0; i < fanout; i++ {
+			objects = append(objects, fmt.Sprintf("%sfile-%d", prefix, i))
+			keys++
+		}
+		if depth > 0 {
+			for i := 0; i < fanout; i++ {
+				dir := fmt.Sprintf("%sdir-%d/", prefix, i)
+				objects = append(objects, dir)
+				prefixes = append(prefixes, dir)
+				build(dir, depth-1)
+			}
+		}
+		levels[prefix] = [2][]string{objects, prefixes}
+	}
+	build("", depth)
+
+	return func(prefix string) ([]ObjectInfo, []string, ObjectStorageError) {
+		time.Sleep(latency)
+		level := levels[prefix]
+		objects := make([]ObjectInfo, 0, len(level[0]))
+		for _, name := range level[0] {
+			objects = append(objects, NewObjectInfo(name, 1, time.Time{}))
+		}
+		return objects, level[1], nil
+	}, keys
+}
+
+func objectNames(objects []ObjectInfo) []string {
+	names := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		names = append(names, obj.Name)
+	}
+	return names
+}
+
+func BenchmarkParallelListObjects(b *testing.B) {
+	listLevel, keys := fakeLevels(3, 4, time.Millisecond)
+
+	serial, se := ParallelListObjects("", ListOptions{Recursive: true, IncludeDirectories: true, ConcurrentNum: 1}, listLevel)
+	if se != nil {
+		b.Fatal(se)
+	}
+	expected := objectNames(serial)
+	if len(expected) != keys {
+		b.Fatalf("listed %d objects, expected %d", len(expected), keys)
+	}
+	seen := make(map[string]bool)
+	for _, name := range expected {
+		if seen[name] {
+			b.Fatalf("duplicated object %s", name)
+		}
+		seen[name] = true
+	}
+
+	for _, concurrentNum := range []int{1, 8} {
+		opt := ListOptions{Recursive: true, IncludeDirectories: true, ConcurrentNum: concurrentNum}
+		b.Run(fmt.Sprintf("ConcurrentNum=%d", concurrentNum), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				objects, se := ParallelListObjects("", opt, listLevel)
+				if se != nil {
+					b.Fatal(se)
+				}
+				if names := objectNames(objects); !reflect.DeepEqual(names, expected) {
+					b.Fatalf("listing is not deterministic: %v", names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package common
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SyncDirection int
+
+const (
+	SyncUpload   SyncDirection = iota // 本地目录同步到对象前缀
+	SyncDownload                      // 对象前缀同步到本地目录
+)
+
+// SyncCompare 判断两端文件是否一致的方式，可以组合使用
+type SyncCompare int
+
+const (
+	SyncCompareSize     SyncCompare = 1 << iota // 比较大小
+	SyncCompareModTime                          // 源端比目标端新时同步
+	SyncCompareChecksum                         // 比较 MD5 与 ETag，分片上传的对象退化为比较大小
+)
+
+type SyncActionType string
+
+const (
+	SyncActionUpload   SyncActionType = "upload"
+	SyncActionDownload SyncActionType = "download"
+	SyncActionDelete   SyncActionType = "delete"
+)
+
+type SyncAction struct {
+	Type      SyncActionType
+	Key       string
+	LocalPath string
+}
+
+type SyncResult struct {
+	Actions []SyncAction // 已执行的操作，DryRun 时为将要执行的操作
+	Skipped []string     // 两端一致而跳过的对象键
+	Failed  []KeyError
+}
+
+func (r *SyncResult) HasErrors() bool {
+	return len(r.Failed) > 0
+}
+
+// Sync synchronizes localDir and the prefix in the given direction, like rsync.
+// Files and objects that fail to be synchronized are reported in the Failed of the result
+// without stopping the others; an error is returned only for invalid options or if
+// localDir or the prefix cannot be listed.
+func Sync(storage Storage, provider BackendType, localDir, prefix string, options *SyncOptions) (*SyncResult, ObjectStorageError) {
+	if options == nil {
+		options = &SyncOptions{}
+	}
+	if err := options.Validate(); err != nil {
+		return nil, NewInvalidArgumentError(provider, err.Error())
+	}
+
+	objectKeyPrefix := ToDirPrefix(prefix)
+	if objectKeyPrefix == "" && options.Direction == SyncUpload && options.DeleteExtraneous && !options.AllowDeleteBucketRoot {
+		return nil, NewInvalidArgumentError(provider, "deleting extraneous objects of the bucket root requires AllowDeleteBucketRoot")
+	}
+
+	localFiles, se := listLocalFiles(provider, localDir, options.Direction == SyncUpload)
+	if se != nil {
+		return nil, se
+	}
+
+	objects, se := storage.ListObjects(ListOptions{
+		ObjectKeyPrefix: objectKeyPrefix,
+		Recursive:       true,
+	})
+	if se != nil {
+		return nil, se
+	}
+
+	result := &SyncResult{}
+	remoteObjects := make(map[string]ObjectInfo, len(objects))
+	for _, obj := range objects {
+		relPath := strings.TrimPrefix(obj.Name, objectKeyPrefix)
+		if _, ok := SafeLocalPath(localDir, relPath); !ok && options.Direction == SyncDownload {
+			result.Failed = append(result.Failed, KeyError{Key: obj.Name, Err: NewInvalidObjectNameError(provider, obj.Name)})
+			continue
+		}
+		if options.IsIncluded(relPath) {
+			remoteObjects[relPath] = obj
+		}
+	}
+	for relPath := range localFiles {
+		if !options.IsIncluded(relPath) {
+			delete(localFiles, relPath)
+		}
+	}
+
+	var actions []SyncAction
+	if options.Direction == SyncUpload {
+		actions = planUpload(provider, localDir, objectKeyPrefix, localFiles, remoteObjects, options, result)
+	} else {
+		actions = planDownload(provider, localDir, objectKeyPrefix, localFiles, remoteObjects, options, result)
+	}
+
+	if options.DryRun {
+		result.Actions = actions
+		return result, nil
+	}
+
+	executeSyncActions(storage, provider, actions, remoteObjects, objectKeyPrefix, options, result)
+	return result, nil
+}
+
+func planUpload(provider BackendType, localDir, objectKeyPrefix string, localFiles map[string]os.FileInfo, remoteObjects map[string]ObjectInfo, options *SyncOptions, result *SyncResult) []SyncAction {
+	var actions []SyncAction
+	for _, relPath := range sortedFilePaths(localFiles) {
+		localPath, _ := SafeLocalPath(localDir, relPath)
+		objectKey := objectKeyPrefix + relPath
+		if remote, ok := remoteObjects[relPath]; ok {
+			changed, se := isSyncNeeded(provider, options.GetCompare(), localFiles[relPath], localPath, remote, true)
+			if se != nil {
+				result.Failed = append(result.Failed, KeyError{Key: objectKey, Err: se})
+				continue
+			}
+			if !changed {
+				result.Skipped = append(result.Skipped, objectKey)
+				continue
+			}
+		}
+		actions = append(actions, SyncAction{Type: SyncActionUpload, Key: objectKey, LocalPath: localPath})
+	}
+	if options.DeleteExtraneous {
+		for _, relPath := range sortedObjectPaths(remoteObjects) {
+			if _, ok := localFiles[relPath]; !ok {
+				actions = append(actions, SyncAction{Type: SyncActionDelete, Key: remoteObjects[relPath].Name})
+			}
+		}
+	}
+	return actions
+}
+
+func planDownload(provider BackendType, localDir, objectKeyPrefix string, localFiles map[string]os.FileInfo, remoteObjects map[string]ObjectInfo, options *SyncOptions, result *SyncResult) []SyncAction {
+	var actions []SyncAction
+	for _, relPath := range sortedObjectPaths(remoteObjects) {
+		remote := remoteObjects[relPath]
+		localPath, _ := SafeLocalPath(localDir, relPath)
+		if local, ok := localFiles[relPath]; ok {
+			changed, se := isSyncNeeded(provider, options.GetCompare(), local, localPath, remote, false)
+			if se != nil {
+				result.Failed = append(result.Failed, KeyError{Key: remote.Name, Err: se})
+				continue
+			}
+			if !changed {
+				result.Skipped = append(result.Skipped, remote.Name)
+				continue
+			}
+		}
+		actions = append(actions, SyncAction{Type: SyncActionDownload, Key: remote.Name, LocalPath: localPath})
+	}
+	if options.DeleteExtraneous {
+		for _, relPath := range sortedFilePaths(localFiles) {
+			if _, ok := remoteObjects[relPath]; !ok {
+				localPath, _ := SafeLocalPath(localDir, relPath)
+				actions = append(actions, SyncAction{Type: SyncActionDelete, Key: objectKeyPrefix + relPath, LocalPath: localPath})
+			}
+		}
+	}
+	return actions
+}
+
+func executeSyncActions(storage Storage, provider BackendType, actions []SyncAction, remoteObjects map[string]ObjectInfo, objectKeyPrefix string, options *SyncOptions, result *SyncResult) {
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		deleteKeys []string
+	)
+
+	actionCh := make(chan SyncAction, len(actions))
+	for _, action := range actions {
+		// remote deletions are sent in batches below
+		if action.Type == SyncActionDelete && options.Direction == SyncUpload {
+			deleteKeys = append(deleteKeys, action.Key)
+			continue
+		}
+		actionCh <- action
+	}
+	close(actionCh)
+
+	for i := 0; i < options.GetConcurrentNum(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for action := range actionCh {
+				var se ObjectStorageError
+				switch action.Type {
+				case SyncActionUpload:
+					se = storage.FPutObject(action.LocalPath, action.Key)
+				case SyncActionDownload:
+					remote := remoteObjects[strings.TrimPrefix(action.Key, objectKeyPrefix)]
+					se = downloadFile(storage, provider, action.Key, action.LocalPath, remote.LastModified)
+				case SyncActionDelete:
+					if err := os.Remove(action.LocalPath); err != nil {
+						se = NewLocalFileError(provider, action.LocalPath, err)
+					}
+				}
+				mu.Lock()
+				if se != nil {
+					result.Failed = append(result.Failed, KeyError{Key: action.Key, Err: se})
+				} else {
+					result.Actions = append(result.Actions, action)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(deleteKeys) > 0 {
+		deleteResult, se := storage.DeleteObjects(deleteKeys)
+		if se != nil {
+			for _, objectKey := range deleteKeys {
+				result.Failed = append(result.Failed, KeyError{Key: objectKey, Err: se})
+			}
+			return
+		}
+		for _, objectKey := range deleteResult.Deleted {
+			result.Actions = append(result.Actions, SyncAction{Type: SyncActionDelete, Key: objectKey})
+		}
+		result.Failed = append(result.Failed, deleteResult.Failed...)
+	}
+}
+
+// downloadFile creates the parent directories, downloads the object and sets the
+// modification time of the file to the one of the object so the next sync skips it.
+func downloadFile(storage Storage, provider BackendType, objectKey, localPath string, lastModified time.Time) ObjectStorageError {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return NewLocalFileError(provider, localPath, err)
+	}
+	if se := storage.FGetObject(objectKey, localPath); se != nil {
+		return se
+	}
+	if !lastModified.IsZero() {
+		if err := os.Chtimes(localPath, lastModified, lastModified); err != nil {
+			return NewLocalFileError(provider, localPath, err)
+		}
+	}
+	return nil
+}
+
+func isSyncNeeded(provider BackendType, compare SyncCompare, local os.FileInfo, localPath string, remote ObjectInfo, upload bool) (bool, ObjectStorageError) {
+	if compare&SyncCompareSize != 0 && local.Size() != remote.Size {
+		return true, nil
+	}
+	if compare&SyncCompareModTime != 0 {
+		if upload && local.ModTime().After(remote.LastModified) {
+			return true, nil
+		}
+		if !upload && remote.LastModified.After(local.ModTime()) {
+			return true, nil
+		}
+	}
+	if compare&SyncCompareChecksum != 0 {
+		// the ETag of multipart uploads is not the MD5 of the content
+		if len(remote.ETag) != md5.Size*2 {
+			return local.Size() != remote.Size, nil
+		}
+		checksum, err := fileMD5(localPath)
+		if err != nil {
+			return false, NewLocalFileError(provider, localPath, err)
+		}
+		return !strings.EqualFold(checksum, remote.ETag), nil
+	}
+	return false, nil
+}
+
+func fileMD5(localPath string) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// listLocalFiles returns the regular files under localDir by their slash separated relative path.
+// A missing localDir is an error only if it must exist.
+func listLocalFiles(provider BackendType, localDir string, mustExist bool) (map[string]os.FileInfo, ObjectStorageError) {
+	files := make(map[string]os.FileInfo)
+
+	stat, err := os.Stat(localDir)
+	if err != nil || !stat.IsDir() {
+		if mustExist || (err == nil && !stat.IsDir()) {
+			return nil, NewNoSuchDirectoryError(provider, localDir)
+		}
+		return files, nil
+	}
+
+	err = filepath.WalkDir(localDir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = info
+		return nil
+	})
+	if err != nil {
+		return nil, NewLocalFileError(provider, localDir, err)
+	}
+	return files, nil
+}
+
+func sortedFilePaths(files map[string]os.FileInfo) []string {
+	relPaths := make([]string, 0, len(files))
+	for relPath := range files {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+	return relPaths
+}
+
+func sortedObjectPaths(objects map[string]ObjectInfo) []string {
+	relPaths := make([]string, 0, len(objects))
+	for relPath := range objects {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+	return relPaths
+}
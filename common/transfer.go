@@ -0,0 +1,58 @@
+package common
+
+// TransferObject copies srcObjectKey of src to destObjectKey of dest, which may
+// be another bucket or another backend. A server-side copy is tried first when
+// both storages are served by the same endpoint, otherwise the object is streamed
+// from src to dest together with its content type and user metadata.
+func TransferObject(src Storage, srcObjectKey string, dest Storage, destObjectKey string, options *TransferOptions) ObjectStorageError {
+	if options == nil {
+		options = &TransferOptions{}
+	}
+
+	srcLocation, destLocation := src.GetLocation(), dest.GetLocation()
+
+	invalidObjectKey := FindFirstInvalidObject(srcObjectKey, destObjectKey)
+	if invalidObjectKey != "" {
+		return NewInvalidObjectNameError(destLocation.Type, invalidObjectKey)
+	}
+
+	if !options.Overwrite {
+		exist, se := dest.ObjectExist(destObjectKey)
+		if se != nil {
+			return se
+		}
+		if exist {
+			return NewObjectAlreadyExistError(destLocation.Type, destObjectKey)
+		}
+	}
+
+	if !options.DisableServerSideCopy && srcLocation.SameService(destLocation) {
+		se := dest.CopyObjectFromBucket(srcLocation.BucketName, srcObjectKey, destObjectKey, &CopyOptions{Overwrite: true})
+		// the credentials of dest may not be allowed to read src, stream it instead
+		if se == nil || se.GetCode() != ErrCodeAccessDenied {
+			return se
+		}
+	}
+
+	return streamObject(src, srcObjectKey, dest, destObjectKey)
+}
+
+func streamObject(src Storage, srcObjectKey string, dest Storage, destObjectKey string) ObjectStorageError {
+	objInfo, se := src.StatObject(srcObjectKey)
+	if se != nil {
+		return se
+	}
+
+	data, se := src.GetObject(srcObjectKey)
+	if se != nil {
+		return se
+	}
+	reader := data.Reader()
+	defer reader.Close()
+
+	return dest.PutObjectWithOptions(destObjectKey, reader, &PutOptions{
+		Metadata:    objInfo.Metadata,
+		ContentType: objInfo.ContentType,
+		Size:        objInfo.Size,
+	})
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -42,3 +43,43 @@ func RemoveDirObjects(objects []ObjectInfo) []ObjectInfo {
 	}
 	return ret
 }
+
+// ChunkKeys splits keys into chunks of at most size keys.
+func ChunkKeys(keys []string, size int) [][]string {
+	if size <= 0 {
+		size = MaxDeleteObjectsBatch
+	}
+	chunks := make([][]string, 0, (len(keys)+size-1)/size)
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		chunks = append(chunks, keys[start:end])
+	}
+	return chunks
+}
+
+// ToDirPrefix converts a directory path to the object key prefix of its content,
+// e.g. "/a/b" to "a/b/". The bucket root is represented by an empty prefix.
+func ToDirPrefix(dirPath string) string {
+	prefix := strings.TrimLeft(dirPath, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// SafeLocalPath joins the slash separated relative path of an object to localDir.
+// It returns false if the result would escape localDir, e.g. for keys containing "..".
+func SafeLocalPath(localDir, relPath string) (string, bool) {
+	if relPath == "" || filepath.IsAbs(filepath.FromSlash(relPath)) {
+		return "", false
+	}
+	localPath := filepath.Join(localDir, filepath.FromSlash(relPath))
+	rel, err := filepath.Rel(localDir, localPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return localPath, true
+}
@@ -0,0 +1,10 @@
+package common
+
+type VersioningStatus string
+
+const (
+	// 从未开启过版本控制
+	VersioningUnversioned VersioningStatus = ""
+	VersioningEnabled     VersioningStatus = "Enabled"
+	VersioningSuspended   VersioningStatus = "Suspended"
+)
@@ -0,0 +1,201 @@
+// Package failover combines a primary and a secondary common.Storage, for example an on-premise
+// MinIO and an OSS disaster recovery copy, so that the application keeps working when one is down.
+package failover
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+// WritePolicy decides where the writes go.
+type WritePolicy int
+
+const (
+	// WritePrimaryOnly writes to the active storage only.
+	WritePrimaryOnly WritePolicy = iota
+	// WriteBoth copies every write to the other storage before returning.
+	WriteBoth
+	// WriteAsyncBackfill copies every write to the other storage in the background.
+	WriteAsyncBackfill
+)
+
+// Options 故障转移选项
+type Options struct {
+	WritePolicy WritePolicy // 写入策略，默认 WritePrimaryOnly
+
+	// 连续出现可用性错误的次数达到该值时认为存储不健康，默认 3。
+	// 主存储不健康时，读写都切换到备存储，直到健康检查成功
+	FailureThreshold int
+	// 对不健康的存储进行健康检查的间隔，默认 10s
+	HealthCheckInterval time.Duration
+
+	BackfillQueueSize     int // 每个异步回填协程的队列长度，队列满时写操作等待，默认 1000
+	BackfillConcurrentNum int // 异步回填并发数，默认 1。同一对象的回填总是由同一个协程按顺序执行
+	// 复制到另一个存储失败时调用，包括 WriteBoth 中的同步复制
+	OnReplicationError func(operation, objectKey string, se common.ObjectStorageError)
+}
+
+func (opt *Options) GetFailureThreshold() int {
+	if opt.FailureThreshold <= 0 {
+		return 3
+	}
+	return opt.FailureThreshold
+}
+
+func (opt *Options) GetHealthCheckInterval() time.Duration {
+	if opt.HealthCheckInterval <= 0 {
+		return 10 * time.Second
+	}
+	return opt.HealthCheckInterval
+}
+
+func (opt *Options) GetBackfillQueueSize() int {
+	if opt.BackfillQueueSize <= 0 {
+		return 1000
+	}
+	return opt.BackfillQueueSize
+}
+
+func (opt *Options) GetBackfillConcurrentNum() int {
+	if opt.BackfillConcurrentNum <= 0 {
+		return 1
+	}
+	return opt.BackfillConcurrentNum
+}
+
+// IsAvailabilityError reports whether se means that the storage is unavailable: a
+// retryable failure such as a timeout or a 5xx response, or an open circuit breaker.
+func IsAvailabilityError(se common.ObjectStorageError) bool {
+	return se != nil && (se.IsRetryable() || se.GetCode() == common.ErrCodeCircuitOpen)
+}
+
+// IsNotFoundError reports whether se means that the object, version or bucket does not exist.
+func IsNotFoundError(se common.ObjectStorageError) bool {
+	return errors.Is(common.ToError(se), common.ErrNotFound)
+}
+
+func newUnavailableError(provider common.BackendType, message string) common.ObjectStorageError {
+	return common.NewStorageError(provider, common.ErrCodeServiceUnavailable, message, errors.New(message))
+}
+
+// health tracks the availability of one storage.
+type health struct {
+	storage   common.Storage
+	threshold int
+
+	mu       sync.Mutex
+	failures int
+	healthy  bool
+}
+
+func (h *health) isHealthy() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.healthy
+}
+
+// record updates the health with the result of a call. Only availability errors count as
+// failures, other errors show that the storage answered.
+func (h *health) record(se common.ObjectStorageError) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !IsAvailabilityError(se) {
+		h.failures = 0
+		h.healthy = true
+		return
+	}
+	h.failures++
+	if h.failures >= h.threshold {
+		h.healthy = false
+	}
+}
+
+// check probes an unhealthy storage by checking that its bucket exists.
+func (h *health) check() {
+	if h.isHealthy() {
+		return
+	}
+	_, se := h.storage.BucketExists(h.storage.GetLocation().BucketName)
+	if se == nil {
+		h.record(nil)
+	}
+}
+
+type replication struct {
+	operation string
+	objectKey string
+	shard     int // backfill worker
+	run       func() common.ObjectStorageError
+}
+
+// recorder records the results of the calls made by replications on the health of storage.
+// It covers the calls of common.TransferObject and of the replications of directory markers,
+// deletes and tags.
+type recorder struct {
+	common.Storage
+	health *health
+}
+
+func (r *recorder) ObjectExist(objectKey string) (exist bool, se common.ObjectStorageError) {
+	exist, se = r.Storage.ObjectExist(objectKey)
+	r.health.record(se)
+	return exist, se
+}
+
+func (r *recorder) StatObject(objectKey string) (info *common.ObjectInfo, se common.ObjectStorageError) {
+	info, se = r.Storage.StatObject(objectKey)
+	r.health.record(se)
+	return info, se
+}
+
+func (r *recorder) GetObject(objectKey string) (data common.IObjectData, se common.ObjectStorageError) {
+	data, se = r.Storage.GetObject(objectKey)
+	r.health.record(se)
+	return data, se
+}
+
+func (r *recorder) PutObjectWithOptions(objectKey string, reader io.Reader, options *common.PutOptions) common.ObjectStorageError {
+	se := r.Storage.PutObjectWithOptions(objectKey, reader, options)
+	r.health.record(se)
+	return se
+}
+
+func (r *recorder) CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey string, options *common.CopyOptions) common.ObjectStorageError {
+	se := r.Storage.CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey, options)
+	r.health.record(se)
+	return se
+}
+
+func (r *recorder) MkDir(dirPath string) common.ObjectStorageError {
+	se := r.Storage.MkDir(dirPath)
+	r.health.record(se)
+	return se
+}
+
+func (r *recorder) DeleteObject(objectKey string) common.ObjectStorageError {
+	se := r.Storage.DeleteObject(objectKey)
+	r.health.record(se)
+	return se
+}
+
+func (r *recorder) DeleteObjects(objectKeys []string) (result *common.DeleteObjectsResult, se common.ObjectStorageError) {
+	result, se = r.Storage.DeleteObjects(objectKeys)
+	r.health.record(se)
+	return result, se
+}
+
+func (r *recorder) PutObjectTags(objectKey string, tags map[string]string) common.ObjectStorageError {
+	se := r.Storage.PutObjectTags(objectKey, tags)
+	r.health.record(se)
+	return se
+}
+
+func (r *recorder) DeleteObjectTags(objectKey string) common.ObjectStorageError {
+	se := r.Storage.DeleteObjectTags(objectKey)
+	r.health.record(se)
+	return se
+}
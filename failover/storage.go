@@ -0,0 +1,654 @@
+package failover
+
+import (
+	"hash/fnv"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+// page token prefixes of the storage that issued the token
+const (
+	primaryTokenPrefix   = "p:"
+	secondaryTokenPrefix = "s:"
+)
+
+// Storage is a common.Storage over a primary and a secondary storage.
+//
+// Object reads go to the active storage and fall back to the other one when the object is
+// not found or the active storage is unavailable. Writes go to the active storage and are
+// copied to the other one according to Options.WritePolicy. The active storage is the
+// primary, unless it became unhealthy after Options.FailureThreshold consecutive availability
+// errors, in which case it is the secondary until a health check of the primary succeeds.
+// Writes made to the secondary while the primary is down are not copied back, deletes of
+// objects not found in the primary are therefore also made to the secondary.
+//
+// Bucket, versioning and object version operations go to the primary only.
+// Composite operations, such as CopyDir, are built on the calls of the failover storage.
+type Storage struct {
+	primary   *health
+	secondary *health
+	options   *Options
+
+	// backfill queues of the workers, the replications of an object key always go to the same
+	// worker so that they run in order
+	backfill  []chan replication
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	// closed rejects the backfills queued after Close, writers hold the read lock while queueing
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ common.Storage = (*Storage)(nil)
+
+// New returns a failover storage and starts its health checks and, with WriteAsyncBackfill,
+// its backfill workers. Call Close to stop them. A nil options uses the defaults.
+func New(primary, secondary common.Storage, options *Options) *Storage {
+	if options == nil {
+		options = &Options{}
+	}
+	s := &Storage{
+		primary:   &health{storage: primary, threshold: options.GetFailureThreshold(), healthy: true},
+		secondary: &health{storage: secondary, threshold: options.GetFailureThreshold(), healthy: true},
+		options:   options,
+		done:      make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.checkHealth()
+
+	if options.WritePolicy == WriteAsyncBackfill {
+		s.backfill = make([]chan replication, options.GetBackfillConcurrentNum())
+		for i := range s.backfill {
+			s.backfill[i] = make(chan replication, options.GetBackfillQueueSize())
+			s.wg.Add(1)
+			go s.runBackfill(s.backfill[i])
+		}
+	}
+	return s
+}
+
+// Close stops the health checks, runs the queued backfills and waits for them.
+func (s *Storage) Close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		close(s.done)
+		s.wg.Wait()
+	})
+}
+
+// Healthy reports whether the primary and the secondary storage are healthy.
+func (s *Storage) Healthy() (primary, secondary bool) {
+	return s.primary.isHealthy(), s.secondary.isHealthy()
+}
+
+// Primary returns the primary storage.
+func (s *Storage) Primary() common.Storage {
+	return s.primary.storage
+}
+
+// Secondary returns the secondary storage.
+func (s *Storage) Secondary() common.Storage {
+	return s.secondary.storage
+}
+
+func (s *Storage) checkHealth() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.options.GetHealthCheckInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.primary.check()
+			s.secondary.check()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Storage) runBackfill(backfill chan replication) {
+	defer s.wg.Done()
+	for {
+		select {
+		case r := <-backfill:
+			s.runReplication(r)
+		case <-s.done:
+			for {
+				select {
+				case r := <-backfill:
+					s.runReplication(r)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Storage) provider() common.BackendType {
+	return s.primary.storage.GetLocation().Type
+}
+
+// active returns the storage to call first and the one to fall back to.
+func (s *Storage) active() (*health, *health) {
+	if !s.primary.isHealthy() && s.secondary.isHealthy() {
+		return s.secondary, s.primary
+	}
+	return s.primary, s.secondary
+}
+
+// read calls fn on the active storage and, if it fails with an availability error, or with
+// a not found error when fallbackNotFound is set, on the other storage if it is healthy.
+func (s *Storage) read(fallbackNotFound bool, fn func(storage common.Storage) common.ObjectStorageError) common.ObjectStorageError {
+	first, second := s.active()
+	se := first.call(fn)
+	if se == nil || !second.isHealthy() {
+		return se
+	}
+	if !IsAvailabilityError(se) && !(fallbackNotFound && IsNotFoundError(se)) {
+		return se
+	}
+	return second.call(fn)
+}
+
+// write calls fn on the active storage and, if it succeeds on the primary, runs the
+// replications returned by replicate according to the write policy. A nil replicate does
+// not replicate.
+func (s *Storage) write(fn func(storage common.Storage) common.ObjectStorageError, replicate func() []replication) common.ObjectStorageError {
+	first, _ := s.active()
+	se := first.call(fn)
+	if se != nil || first != s.primary || replicate == nil {
+		return se
+	}
+	for _, r := range replicate() {
+		s.replicate(r)
+	}
+	return nil
+}
+
+func (s *Storage) replicate(r replication) {
+	switch s.options.WritePolicy {
+	case WriteBoth:
+		if !s.secondary.isHealthy() {
+			s.replicationError(r, newUnavailableError(s.secondary.storage.GetLocation().Type, "secondary storage is unhealthy"))
+			return
+		}
+		s.runReplication(r)
+	case WriteAsyncBackfill:
+		s.queueBackfill(r)
+	}
+}
+
+// newReplication returns the replication of operation on objectKey, run is called with the
+// primary and the secondary storage.
+func (s *Storage) newReplication(operation, objectKey string, run func(from, to common.Storage) common.ObjectStorageError) replication {
+	return replication{
+		operation: operation,
+		objectKey: objectKey,
+		shard:     s.shard(objectKey),
+		run: func() common.ObjectStorageError {
+			return run(&recorder{s.primary.storage, s.primary}, &recorder{s.secondary.storage, s.secondary})
+		},
+	}
+}
+
+// replicateKey returns a replicate function of write with the single replication of operation on objectKey.
+func (s *Storage) replicateKey(operation, objectKey string, run func(from, to common.Storage) common.ObjectStorageError) func() []replication {
+	return func() []replication {
+		return []replication{s.newReplication(operation, objectKey, run)}
+	}
+}
+
+// shard returns the backfill worker of objectKey.
+func (s *Storage) shard(objectKey string) int {
+	if len(s.backfill) <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(objectKey))
+	return int(h.Sum32() % uint32(len(s.backfill)))
+}
+
+// queueBackfill waits for room in the backfill queue. After Close it reports the replication
+// as failed instead, since the workers may already have stopped.
+func (s *Storage) queueBackfill(r replication) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		s.replicationError(r, newUnavailableError(s.secondary.storage.GetLocation().Type, "failover storage is closed"))
+		return
+	}
+	s.backfill[r.shard] <- r
+}
+
+// runReplication runs r. The calls of r record their result on the health of the storage
+// they are made to, so that a primary failing to be read does not mark the secondary unhealthy.
+func (s *Storage) runReplication(r replication) {
+	if se := r.run(); se != nil {
+		s.replicationError(r, se)
+	}
+}
+
+func (s *Storage) replicationError(r replication, se common.ObjectStorageError) {
+	if s.options.OnReplicationError != nil {
+		s.options.OnReplicationError(r.operation, r.objectKey, se)
+	}
+}
+
+func (h *health) call(fn func(storage common.Storage) common.ObjectStorageError) common.ObjectStorageError {
+	se := fn(h.storage)
+	h.record(se)
+	return se
+}
+
+// replicateObject copies objectKey from the primary to the secondary. Directory markers are
+// created with MkDir, since common.TransferObject rejects their keys.
+func replicateObject(objectKey string) func(from, to common.Storage) common.ObjectStorageError {
+	return func(from, to common.Storage) common.ObjectStorageError {
+		if strings.HasSuffix(objectKey, "/") {
+			return to.MkDir(objectKey)
+		}
+		return common.TransferObject(from, objectKey, to, objectKey, &common.TransferOptions{
+			CopyOptions: common.CopyOptions{Overwrite: true},
+		})
+	}
+}
+
+// replicateDelete deletes objectKey from the secondary.
+func replicateDelete(objectKey string) func(from, to common.Storage) common.ObjectStorageError {
+	return func(from, to common.Storage) common.ObjectStorageError {
+		se := to.DeleteObject(objectKey)
+		if IsNotFoundError(se) {
+			return nil
+		}
+		return se
+	}
+}
+
+func (s *Storage) GetLocation() common.StorageLocation {
+	return s.primary.storage.GetLocation()
+}
+
+func (s *Storage) CreateBucket(bucketName string) common.ObjectStorageError {
+	return s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.CreateBucket(bucketName)
+	})
+}
+
+func (s *Storage) BucketExists(bucketName string) (exist bool, se common.ObjectStorageError) {
+	se = s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		exist, se = storage.BucketExists(bucketName)
+		return se
+	})
+	return exist, se
+}
+
+func (s *Storage) EnsureBucket(bucketName string) common.ObjectStorageError {
+	return s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.EnsureBucket(bucketName)
+	})
+}
+
+func (s *Storage) EnableVersioning(bucketName string) common.ObjectStorageError {
+	return s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.EnableVersioning(bucketName)
+	})
+}
+
+func (s *Storage) SuspendVersioning(bucketName string) common.ObjectStorageError {
+	return s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.SuspendVersioning(bucketName)
+	})
+}
+
+func (s *Storage) GetVersioningStatus(bucketName string) (status common.VersioningStatus, se common.ObjectStorageError) {
+	se = s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		status, se = storage.GetVersioningStatus(bucketName)
+		return se
+	})
+	return status, se
+}
+
+func (s *Storage) GetBucketLifecycle(bucketName string) (config *common.LifecycleConfiguration, se common.ObjectStorageError) {
+	se = s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		config, se = storage.GetBucketLifecycle(bucketName)
+		return se
+	})
+	return config, se
+}
+
+func (s *Storage) PutBucketLifecycle(bucketName string, config *common.LifecycleConfiguration) common.ObjectStorageError {
+	return s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.PutBucketLifecycle(bucketName, config)
+	})
+}
+
+func (s *Storage) DeleteBucketLifecycle(bucketName string) common.ObjectStorageError {
+	return s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.DeleteBucketLifecycle(bucketName)
+	})
+}
+
+// ObjectExist falls back to the other storage when the object does not exist in the active one.
+func (s *Storage) ObjectExist(objectKey string) (exist bool, se common.ObjectStorageError) {
+	se = s.read(true, func(storage common.Storage) common.ObjectStorageError {
+		exist, se = storage.ObjectExist(objectKey)
+		if se == nil && !exist {
+			return common.NewObjectNotFoundError(storage.GetLocation().Type, objectKey)
+		}
+		return se
+	})
+	if IsNotFoundError(se) {
+		return false, nil
+	}
+	return exist, se
+}
+
+func (s *Storage) StatObject(objectKey string) (info *common.ObjectInfo, se common.ObjectStorageError) {
+	se = s.read(true, func(storage common.Storage) common.ObjectStorageError {
+		info, se = storage.StatObject(objectKey)
+		return se
+	})
+	return info, se
+}
+
+func (s *Storage) GetObject(objectKey string) (data common.IObjectData, se common.ObjectStorageError) {
+	se = s.read(true, func(storage common.Storage) common.ObjectStorageError {
+		data, se = storage.GetObject(objectKey)
+		return se
+	})
+	return data, se
+}
+
+func (s *Storage) FGetObject(objectKey, localFilePath string) common.ObjectStorageError {
+	return s.read(true, func(storage common.Storage) common.ObjectStorageError {
+		return storage.FGetObject(objectKey, localFilePath)
+	})
+}
+
+func (s *Storage) FPutObject(localFilePath, objectKey string) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.FPutObject(localFilePath, objectKey)
+	}, s.replicateKey("FPutObject", objectKey, replicateObject(objectKey)))
+}
+
+func (s *Storage) FGetDir(prefix, localDir string, options *common.LocalDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.FGetDir(s, s.provider(), prefix, localDir, options)
+}
+
+func (s *Storage) FPutDir(localDir, prefix string, options *common.LocalDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.FPutDir(s, s.provider(), localDir, prefix, options)
+}
+
+// PutObject replicates the object by reading it back from the primary, since reader can only be read once.
+func (s *Storage) PutObject(objectKey string, reader io.Reader) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.PutObject(objectKey, reader)
+	}, s.replicateKey("PutObject", objectKey, replicateObject(objectKey)))
+}
+
+func (s *Storage) PutObjectWithOptions(objectKey string, reader io.Reader, options *common.PutOptions) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.PutObjectWithOptions(objectKey, reader, options)
+	}, s.replicateKey("PutObject", objectKey, replicateObject(objectKey)))
+}
+
+func (s *Storage) FPutObjectWithOptions(localFilePath, objectKey string, options *common.PutOptions) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.FPutObjectWithOptions(localFilePath, objectKey, options)
+	}, s.replicateKey("FPutObject", objectKey, replicateObject(objectKey)))
+}
+
+// DeleteObject deletes the object from the secondary even if it is not found in the primary,
+// since it may have been written to the secondary only while the primary was down. It
+// returns a not found error only if the object is found in neither storage.
+func (s *Storage) DeleteObject(objectKey string) common.ObjectStorageError {
+	first, _ := s.active()
+	if first != s.primary {
+		return first.call(func(storage common.Storage) common.ObjectStorageError {
+			return storage.DeleteObject(objectKey)
+		})
+	}
+
+	se := s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.DeleteObject(objectKey)
+	})
+	if se == nil {
+		s.replicate(s.newReplication("DeleteObject", objectKey, replicateDelete(objectKey)))
+		return nil
+	}
+	if !IsNotFoundError(se) || !s.secondary.isHealthy() {
+		return se
+	}
+	return s.secondary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.DeleteObject(objectKey)
+	})
+}
+
+// DeleteObjects replicates the deletes of the objects of each backfill worker in one batch.
+func (s *Storage) DeleteObjects(objectKeys []string) (result *common.DeleteObjectsResult, se common.ObjectStorageError) {
+	se = s.write(func(storage common.Storage) common.ObjectStorageError {
+		result, se = storage.DeleteObjects(objectKeys)
+		return se
+	}, func() []replication {
+		shards := make(map[int][]string)
+		for _, objectKey := range result.Deleted {
+			shard := s.shard(objectKey)
+			shards[shard] = append(shards[shard], objectKey)
+		}
+		replications := make([]replication, 0, len(shards))
+		for shard, deleted := range shards {
+			deleted := deleted
+			r := s.newReplication("DeleteObjects", "", func(from, to common.Storage) common.ObjectStorageError {
+				_, se := to.DeleteObjects(deleted)
+				return se
+			})
+			r.shard = shard
+			replications = append(replications, r)
+		}
+		return replications
+	})
+	return result, se
+}
+
+func (s *Storage) DeletePrefix(prefix string, options *common.DeletePrefixOptions) (*common.DeleteObjectsResult, common.ObjectStorageError) {
+	return common.DeletePrefix(s, s.provider(), prefix, options)
+}
+
+// ListObjects falls back to the other storage only when the active one is unavailable,
+// a listing of the active storage is never completed with the other one.
+func (s *Storage) ListObjects(options common.ListOptions) (objects []common.ObjectInfo, se common.ObjectStorageError) {
+	se = s.read(false, func(storage common.Storage) common.ObjectStorageError {
+		objects, se = storage.ListObjects(options)
+		return se
+	})
+	return objects, se
+}
+
+// WalkObjects falls back to the other storage only when the active one is unavailable
+// before the first page is passed to fn.
+func (s *Storage) WalkObjects(options common.ListOptions, fn common.ObjectPageFunc) common.ObjectStorageError {
+	delivered := false
+	first, second := s.active()
+	walk := func(storage common.Storage) common.ObjectStorageError {
+		return storage.WalkObjects(options, func(objects []common.ObjectInfo) bool {
+			delivered = true
+			return fn(objects)
+		})
+	}
+	se := first.call(walk)
+	if se == nil || delivered || !IsAvailabilityError(se) || !second.isHealthy() {
+		return se
+	}
+	return second.call(walk)
+}
+
+// ListPage prefixes the page tokens with the storage that issued them, so that a listing
+// started on one storage continues on it even if the active storage changes.
+func (s *Storage) ListPage(options common.ListOptions, pageToken string) ([]common.ObjectInfo, string, common.ObjectStorageError) {
+	var h *health
+	switch {
+	case pageToken == "":
+	case strings.HasPrefix(pageToken, primaryTokenPrefix):
+		h, pageToken = s.primary, strings.TrimPrefix(pageToken, primaryTokenPrefix)
+	case strings.HasPrefix(pageToken, secondaryTokenPrefix):
+		h, pageToken = s.secondary, strings.TrimPrefix(pageToken, secondaryTokenPrefix)
+	default:
+		return nil, "", common.NewInvalidArgumentError(s.provider(), "malformed page token")
+	}
+
+	var (
+		objects   []common.ObjectInfo
+		nextToken string
+		issuer    *health
+	)
+	list := func(h *health) common.ObjectStorageError {
+		var se common.ObjectStorageError
+		se = h.call(func(storage common.Storage) common.ObjectStorageError {
+			objects, nextToken, se = storage.ListPage(options, pageToken)
+			return se
+		})
+		if se == nil {
+			issuer = h
+		}
+		return se
+	}
+
+	var se common.ObjectStorageError
+	if h != nil {
+		se = list(h)
+	} else {
+		first, second := s.active()
+		se = list(first)
+		if IsAvailabilityError(se) && second.isHealthy() {
+			se = list(second)
+		}
+	}
+	if se != nil {
+		return nil, "", se
+	}
+	if nextToken == "" {
+		return objects, "", nil
+	}
+	if issuer == s.primary {
+		return objects, primaryTokenPrefix + nextToken, nil
+	}
+	return objects, secondaryTokenPrefix + nextToken, nil
+}
+
+func (s *Storage) CopyObject(srcObjectKey, destObjectKey string, options *common.CopyOptions) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.CopyObject(srcObjectKey, destObjectKey, options)
+	}, s.replicateKey("CopyObject", destObjectKey, replicateObject(destObjectKey)))
+}
+
+func (s *Storage) CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey string, options *common.CopyOptions) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey, options)
+	}, s.replicateKey("CopyObjectFromBucket", destObjectKey, replicateObject(destObjectKey)))
+}
+
+func (s *Storage) MoveObject(srcObjectKey, destObjectKey string, options *common.MoveOptions) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.MoveObject(srcObjectKey, destObjectKey, options)
+	}, func() []replication {
+		replications := []replication{s.newReplication("MoveObject", destObjectKey, replicateObject(destObjectKey))}
+		if options == nil || !options.PreserveSource {
+			replications = append(replications, s.newReplication("MoveObject", srcObjectKey, replicateDelete(srcObjectKey)))
+		}
+		return replications
+	})
+}
+
+func (s *Storage) GetObjectTags(objectKey string) (tags map[string]string, se common.ObjectStorageError) {
+	se = s.read(true, func(storage common.Storage) common.ObjectStorageError {
+		tags, se = storage.GetObjectTags(objectKey)
+		return se
+	})
+	return tags, se
+}
+
+func (s *Storage) PutObjectTags(objectKey string, tags map[string]string) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.PutObjectTags(objectKey, tags)
+	}, s.replicateKey("PutObjectTags", objectKey, func(from, to common.Storage) common.ObjectStorageError {
+		return to.PutObjectTags(objectKey, tags)
+	}))
+}
+
+func (s *Storage) DeleteObjectTags(objectKey string) common.ObjectStorageError {
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.DeleteObjectTags(objectKey)
+	}, s.replicateKey("DeleteObjectTags", objectKey, func(from, to common.Storage) common.ObjectStorageError {
+		se := to.DeleteObjectTags(objectKey)
+		if IsNotFoundError(se) {
+			return nil
+		}
+		return se
+	}))
+}
+
+func (s *Storage) ListObjectVersions(options common.ListOptions) (objects []common.ObjectInfo, se common.ObjectStorageError) {
+	se = s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		objects, se = storage.ListObjectVersions(options)
+		return se
+	})
+	return objects, se
+}
+
+func (s *Storage) GetObjectVersion(objectKey, versionID string) (data common.IObjectData, se common.ObjectStorageError) {
+	se = s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		data, se = storage.GetObjectVersion(objectKey, versionID)
+		return se
+	})
+	return data, se
+}
+
+func (s *Storage) DeleteObjectVersion(objectKey, versionID string) common.ObjectStorageError {
+	return s.primary.call(func(storage common.Storage) common.ObjectStorageError {
+		return storage.DeleteObjectVersion(objectKey, versionID)
+	})
+}
+
+// RestoreObjectVersion replicates the restored object, the versions themselves are not replicated.
+func (s *Storage) RestoreObjectVersion(objectKey, versionID string) common.ObjectStorageError {
+	if !s.primary.isHealthy() {
+		return newUnavailableError(s.provider(), "primary storage is unhealthy")
+	}
+	return s.write(func(storage common.Storage) common.ObjectStorageError {
+		return storage.RestoreObjectVersion(objectKey, versionID)
+	}, s.replicateKey("RestoreObjectVersion", objectKey, replicateObject(objectKey)))
+}
+
+func (s *Storage) CopyDir(srcDirPath, destDirPath string, options *common.CopyDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.CopyDir(s, s.provider(), srcDirPath, destDirPath, options)
+}
+
+func (s *Storage) MoveDir(srcDirPath, destDirPath string, options *common.MoveDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.MoveDir(s, s.provider(), srcDirPath, destDirPath, options)
+}
+
+func (s *Storage) MkDir(dirPath string) common.ObjectStorageError {
+	return common.MkDir(s, s.provider(), dirPath)
+}
+
+func (s *Storage) IsDir(dirPath string) (bool, common.ObjectStorageError) {
+	return common.IsDir(s, dirPath)
+}
+
+func (s *Storage) RemoveDir(dirPath string, recursive bool) common.ObjectStorageError {
+	return common.RemoveDir(s, s.provider(), dirPath, recursive)
+}
+
+func (s *Storage) Sync(localDir, prefix string, options *common.SyncOptions) (*common.SyncResult, common.ObjectStorageError) {
+	return common.Sync(s, s.provider(), localDir, prefix, options)
+}
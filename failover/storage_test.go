@@ -0,0 +1,175 @@
+package failover
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+// memStorage is an in-memory storage with the calls used by the failover storage.
+type memStorage struct {
+	common.Storage
+	endpoint    string
+	deleteDelay time.Duration
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemStorage(endpoint string) *memStorage {
+	return &memStorage{endpoint: endpoint, objects: make(map[string][]byte)}
+}
+
+func (m *memStorage) has(objectKey string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.objects[objectKey]
+	return ok
+}
+
+func (m *memStorage) GetLocation() common.StorageLocation {
+	return common.StorageLocation{Type: common.MINIO, Endpoint: m.endpoint, BucketName: "bucket"}
+}
+
+func (m *memStorage) BucketExists(bucketName string) (bool, common.ObjectStorageError) {
+	return true, nil
+}
+
+func (m *memStorage) ObjectExist(objectKey string) (bool, common.ObjectStorageError) {
+	return m.has(objectKey), nil
+}
+
+func (m *memStorage) StatObject(objectKey string) (*common.ObjectInfo, common.ObjectStorageError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[objectKey]
+	if !ok {
+		return nil, common.NewObjectNotFoundError(common.MINIO, objectKey)
+	}
+	return &common.ObjectInfo{Name: objectKey, Size: int64(len(data))}, nil
+}
+
+func (m *memStorage) GetObject(objectKey string) (common.IObjectData, common.ObjectStorageError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[objectKey]
+	if !ok {
+		return nil, common.NewObjectNotFoundError(common.MINIO, objectKey)
+	}
+	return common.NewObjectData(ioutil.NopCloser(bytes.NewReader(data))), nil
+}
+
+func (m *memStorage) PutObject(objectKey string, reader io.Reader) common.ObjectStorageError {
+	return m.PutObjectWithOptions(objectKey, reader, nil)
+}
+
+func (m *memStorage) PutObjectWithOptions(objectKey string, reader io.Reader, options *common.PutOptions) common.ObjectStorageError {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return common.NewStorageError(common.MINIO, common.ErrCodeInternalError, err.Error(), err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectKey] = data
+	return nil
+}
+
+func (m *memStorage) MkDir(dirPath string) common.ObjectStorageError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[common.ToDirPrefix(dirPath)] = nil
+	return nil
+}
+
+func (m *memStorage) DeleteObject(objectKey string) common.ObjectStorageError {
+	time.Sleep(m.deleteDelay)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[objectKey]; !ok {
+		return common.NewObjectNotFoundError(common.MINIO, objectKey)
+	}
+	delete(m.objects, objectKey)
+	return nil
+}
+
+func replicationErrors(t *testing.T) func(operation, objectKey string, se common.ObjectStorageError) {
+	return func(operation, objectKey string, se common.ObjectStorageError) {
+		t.Errorf("%s %s: %v", operation, objectKey, se)
+	}
+}
+
+func TestMkDirReplicatesMarker(t *testing.T) {
+	primary, secondary := newMemStorage("primary"), newMemStorage("secondary")
+	s := New(primary, secondary, &Options{WritePolicy: WriteBoth, OnReplicationError: replicationErrors(t)})
+	defer s.Close()
+
+	if se := s.MkDir("studio/100003"); se != nil {
+		t.Fatal(se)
+	}
+	if !secondary.has("studio/100003/") {
+		t.Error("directory marker not replicated to the secondary")
+	}
+}
+
+func TestDeleteObjectOfSecondaryOnly(t *testing.T) {
+	primary, secondary := newMemStorage("primary"), newMemStorage("secondary")
+	s := New(primary, secondary, &Options{WritePolicy: WriteBoth, OnReplicationError: replicationErrors(t)})
+	defer s.Close()
+
+	// written to the secondary while the primary was down
+	secondary.objects["parameter.js"] = []byte("data")
+
+	if se := s.DeleteObject("parameter.js"); se != nil {
+		t.Fatal(se)
+	}
+	if secondary.has("parameter.js") {
+		t.Error("object not deleted from the secondary")
+	}
+	if se := s.DeleteObject("parameter.js"); !IsNotFoundError(se) {
+		t.Errorf("deleting a missing object: got %v, want a not found error", se)
+	}
+}
+
+func TestBackfillOrderOfKey(t *testing.T) {
+	primary, secondary := newMemStorage("primary"), newMemStorage("secondary")
+	secondary.deleteDelay = 5 * time.Millisecond
+	s := New(primary, secondary, &Options{
+		WritePolicy:           WriteAsyncBackfill,
+		BackfillConcurrentNum: 4,
+		OnReplicationError: func(operation, objectKey string, se common.ObjectStorageError) {
+			// a put replicated after the delete of the primary does not find the object
+			if !IsNotFoundError(se) {
+				t.Errorf("%s %s: %v", operation, objectKey, se)
+			}
+		},
+	})
+
+	// the delete must not be replicated after the second put
+	for i := 0; i < 10; i++ {
+		objectKey := fmt.Sprintf("object-%d", i)
+		if se := s.PutObject(objectKey, strings.NewReader("v1")); se != nil {
+			t.Fatal(se)
+		}
+		if se := s.DeleteObject(objectKey); se != nil {
+			t.Fatal(se)
+		}
+		if se := s.PutObject(objectKey, strings.NewReader("v2")); se != nil {
+			t.Fatal(se)
+		}
+	}
+	s.Close()
+
+	for i := 0; i < 10; i++ {
+		objectKey := fmt.Sprintf("object-%d", i)
+		if !secondary.has(objectKey) {
+			t.Errorf("%s missing from the secondary", objectKey)
+		}
+	}
+}
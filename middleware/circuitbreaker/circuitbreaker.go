@@ -0,0 +1,254 @@
+// Package circuitbreaker fails the calls of a common.Storage fast while its backend is unavailable.
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/xuelang-group/go-object-storage/common"
+	"github.com/xuelang-group/go-object-storage/middleware"
+)
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateOpen rejects every call with ErrCodeCircuitOpen.
+	StateOpen
+	// StateHalfOpen lets a few probe calls through to decide whether to close again.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Options 熔断器选项
+type Options struct {
+	ConsecutiveFailures int // 连续失败次数达到该值时打开，默认 5
+	// 时间窗口内失败率达到该值（0~1）时打开，0 表示不按失败率判断
+	FailureRate float64
+	MinRequests int           // 按失败率判断时窗口内的最少调用数，默认 20
+	Window      time.Duration // 统计失败率的时间窗口，默认 1 分钟
+
+	OpenTimeout      time.Duration // 打开后经过该时间进入半开状态，默认 30s
+	HalfOpenRequests int           // 半开状态允许的探测调用数，全部成功后关闭，任一失败则重新打开，默认 1
+
+	// 判断错误是否为后端不可用导致的失败，默认使用错误的 IsRetryable。
+	// 其他错误（例如 NoSuchKey）说明后端可用，按成功处理
+	IsFailure func(se common.ObjectStorageError) bool
+	// 状态变化时调用，调用时持有熔断器的锁，不能调用熔断器的方法
+	OnStateChange func(from, to State)
+}
+
+func (opt *Options) GetConsecutiveFailures() int {
+	if opt.ConsecutiveFailures <= 0 {
+		return 5
+	}
+	return opt.ConsecutiveFailures
+}
+
+func (opt *Options) GetMinRequests() int {
+	if opt.MinRequests <= 0 {
+		return 20
+	}
+	return opt.MinRequests
+}
+
+func (opt *Options) GetWindow() time.Duration {
+	if opt.Window <= 0 {
+		return time.Minute
+	}
+	return opt.Window
+}
+
+func (opt *Options) GetOpenTimeout() time.Duration {
+	if opt.OpenTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return opt.OpenTimeout
+}
+
+func (opt *Options) GetHalfOpenRequests() int {
+	if opt.HalfOpenRequests <= 0 {
+		return 1
+	}
+	return opt.HalfOpenRequests
+}
+
+func (opt *Options) isFailure(se common.ObjectStorageError) bool {
+	if se == nil {
+		return false
+	}
+	if opt.IsFailure != nil {
+		return opt.IsFailure(se)
+	}
+	return se.IsRetryable()
+}
+
+// Breaker is a circuit breaker shared by the calls of the storages it wraps.
+type Breaker struct {
+	options *Options
+
+	mu                  sync.Mutex
+	state               State
+	generation          uint64 // incremented on every state change, results of older calls are ignored
+	consecutiveFailures int
+	windowStart         time.Time
+	requests            int
+	failures            int
+	openedAt            time.Time
+	probes              int
+	probeSuccesses      int
+}
+
+// NewBreaker returns a closed breaker. A nil options uses the defaults.
+func NewBreaker(options *Options) *Breaker {
+	if options == nil {
+		options = &Options{}
+	}
+	return &Breaker{
+		options:     options,
+		windowStart: time.Now(),
+	}
+}
+
+// New returns storage whose calls are guarded by breaker.
+func New(storage common.Storage, breaker *Breaker) *middleware.Storage {
+	return middleware.New(storage, NewInterceptor(breaker))
+}
+
+// NewInterceptor returns the interceptor of New, guarded by breaker. Only the errors accepted
+// by Options.IsFailure count as failures, retryable errors by default, so that NoSuchKey does
+// not open the circuit. Composite operations, such as CopyDir, are not counted, their inner
+// calls are. Place it after retry in a middleware.Chain, so that every attempt is counted and
+// the CircuitOpen error, which is not retryable, ends the retries.
+func NewInterceptor(breaker *Breaker) middleware.Interceptor {
+	return breaker.intercept
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh(time.Now())
+	return b.state
+}
+
+func (b *Breaker) intercept(call *middleware.Call, next middleware.Next) common.ObjectStorageError {
+	if call.Composite {
+		return next()
+	}
+
+	generation, ok := b.before()
+	if !ok {
+		return common.NewCircuitOpenError(call.Location.Type, string(call.Operation))
+	}
+	se := next()
+	b.after(generation, !b.options.isFailure(se))
+	return se
+}
+
+// before reports whether a call may go through, and the generation it belongs to.
+func (b *Breaker) before() (uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh(time.Now())
+
+	switch b.state {
+	case StateOpen:
+		return b.generation, false
+	case StateHalfOpen:
+		if b.probes >= b.options.GetHalfOpenRequests() {
+			return b.generation, false
+		}
+		b.probes++
+	}
+	return b.generation, true
+}
+
+func (b *Breaker) after(generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.refresh(now)
+	if generation != b.generation {
+		return
+	}
+
+	if b.state == StateHalfOpen {
+		if !success {
+			b.setState(StateOpen, now)
+			return
+		}
+		b.probeSuccesses++
+		if b.probeSuccesses >= b.options.GetHalfOpenRequests() {
+			b.setState(StateClosed, now)
+		}
+		return
+	}
+
+	b.requests++
+	if success {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.failures++
+	b.consecutiveFailures++
+	if b.consecutiveFailures >= b.options.GetConsecutiveFailures() || b.failureRateExceeded() {
+		b.setState(StateOpen, now)
+	}
+}
+
+func (b *Breaker) failureRateExceeded() bool {
+	if b.options.FailureRate <= 0 || b.requests < b.options.GetMinRequests() {
+		return false
+	}
+	return float64(b.failures)/float64(b.requests) >= b.options.FailureRate
+}
+
+// refresh moves an open breaker to half-open after the open timeout, and starts a new
+// failure rate window when the current one has elapsed.
+func (b *Breaker) refresh(now time.Time) {
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) >= b.options.GetOpenTimeout() {
+			b.setState(StateHalfOpen, now)
+		}
+	case StateClosed:
+		if now.Sub(b.windowStart) >= b.options.GetWindow() {
+			b.windowStart = now
+			b.requests = 0
+			b.failures = 0
+		}
+	}
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.consecutiveFailures = 0
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+	b.probes = 0
+	b.probeSuccesses = 0
+	if state == StateOpen {
+		b.openedAt = now
+	}
+	if b.options.OnStateChange != nil {
+		b.options.OnStateChange(from, state)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+// putLocalFile uploads the local file with PutObjectWithOptions, so that the read hooks of call see its data.
+func (s *Storage) putLocalFile(call *Call, localFilePath, objectKey string, options *common.PutOptions) common.ObjectStorageError {
+	if !common.PathExists(localFilePath) {
+		return common.NewNoSuchFileError(s.provider(), localFilePath)
+	}
+	file, err := os.Open(localFilePath)
+	if err != nil {
+		return common.NewLocalFileError(s.provider(), localFilePath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return common.NewLocalFileError(s.provider(), localFilePath, err)
+	}
+
+	putOptions := common.PutOptions{}
+	if options != nil {
+		putOptions = *options
+	}
+	putOptions.Size = info.Size()
+	if putOptions.ContentType == "" {
+		putOptions.ContentType = mime.TypeByExtension(filepath.Ext(localFilePath))
+	}
+	return s.inner(call).PutObjectWithOptions(objectKey, call.hookReader(file), &putOptions)
+}
+
+// getLocalFile downloads the object with GetObject, so that the read hooks of call see its data.
+// The object is written to a temporary file which replaces localFilePath once complete.
+func (s *Storage) getLocalFile(call *Call, objectKey, localFilePath string) common.ObjectStorageError {
+	data, se := s.inner(call).GetObject(objectKey)
+	if se != nil {
+		return se
+	}
+	reader := call.hookData(data).Reader()
+	defer reader.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localFilePath), 0755); err != nil {
+		return common.NewLocalFileError(s.provider(), localFilePath, err)
+	}
+	tempFilePath := localFilePath + ".part"
+	file, err := os.Create(tempFilePath)
+	if err != nil {
+		return common.NewLocalFileError(s.provider(), tempFilePath, err)
+	}
+	_, err = io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFilePath, localFilePath)
+	}
+	if err != nil {
+		os.Remove(tempFilePath)
+		return common.NewLocalFileError(s.provider(), localFilePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Package logging writes one structured log entry for every call of a common.Storage.
+package logging
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/xuelang-group/go-object-storage/common"
+	"github.com/xuelang-group/go-object-storage/middleware"
+)
+
+// Level is the severity of a log entry, its values are those of log/slog.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// Field is a key value pair of a structured log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger writes structured log entries, see NewLogrusLogger and NewSlogLogger.
+type Logger interface {
+	Log(ctx context.Context, level Level, msg string, fields ...Field)
+}
+
+// Options 日志选项
+type Options struct {
+	Logger       Logger // 默认使用 logrus.StandardLogger()
+	SuccessLevel Level  // 成功调用的日志级别，默认 LevelInfo
+	ErrorLevel   *Level // 失败调用的日志级别，默认 LevelError
+
+	// 存储配置，其中的 AccessKeyID 和 AccessKeySecret 不会出现在日志中
+	Config *common.Config
+	// 其他不能出现在日志中的字符串，例如 STS 临时凭证
+	Secrets []string
+}
+
+func (opt *Options) GetLogger() Logger {
+	if opt.Logger == nil {
+		return NewLogrusLogger(logrus.StandardLogger())
+	}
+	return opt.Logger
+}
+
+func (opt *Options) GetErrorLevel() Level {
+	if opt.ErrorLevel == nil {
+		return LevelError
+	}
+	return *opt.ErrorLevel
+}
+
+func (opt *Options) getRedactor() *Redactor {
+	secrets := opt.Secrets
+	if opt.Config != nil {
+		secrets = append([]string{opt.Config.AccessKeyID, opt.Config.AccessKeySecret}, secrets...)
+	}
+	return NewRedactor(secrets...)
+}
+
+// New returns storage whose calls are logged with the fields operation, backend, bucket,
+// key, duration, bytes, and on failure error_code, error and request_id. Presigned query
+// strings and the secrets of options are redacted. A nil options uses the defaults.
+//
+// The entries of GetObject and GetObjectVersion are written once the returned data has
+// been read to the end or closed, so that they contain the number of bytes read.
+func New(storage common.Storage, options *Options) *middleware.Storage {
+	return middleware.New(storage, NewInterceptor(options))
+}
+
+// NewInterceptor returns the interceptor of New. Every error is logged at the error level of
+// options. Placed before retry in a middleware.Chain it writes one entry per call, after it
+// one entry per attempt.
+func NewInterceptor(options *Options) middleware.Interceptor {
+	if options == nil {
+		options = &Options{}
+	}
+	l := &logger{
+		logger:       options.GetLogger(),
+		successLevel: options.SuccessLevel,
+		errorLevel:   options.GetErrorLevel(),
+		redactor:     options.getRedactor(),
+	}
+	return l.intercept
+}
+
+type logger struct {
+	logger       Logger
+	successLevel Level
+	errorLevel   Level
+	redactor     *Redactor
+}
+
+func (l *logger) intercept(call *middleware.Call, next middleware.Next) common.ObjectStorageError {
+	// count the bytes of uploads of unknown size
+	var written *int64
+	if call.Reader != nil && call.Size < 0 {
+		var n int64
+		written = &n
+		call.Reader = middleware.HookReader(call.Reader, func(read int) {
+			n += int64(read)
+		})
+	}
+
+	start := time.Now()
+	se := next()
+
+	fields := []Field{
+		{Key: "operation", Value: string(call.Operation)},
+		{Key: "backend", Value: string(call.Location.Type)},
+		{Key: "bucket", Value: call.Bucket},
+		{Key: "key", Value: l.redactor.Redact(call.Key)},
+		{Key: "duration", Value: time.Since(start)},
+	}
+
+	if se == nil {
+		switch {
+		case call.Data != nil:
+			call.Data = l.logOnRead(call, fields)
+			return nil
+		case call.Size >= 0:
+			fields = append(fields, Field{Key: "bytes", Value: call.Size})
+		case written != nil:
+			fields = append(fields, Field{Key: "bytes", Value: *written})
+		}
+		l.logger.Log(call.Context, l.successLevel, "storage call", fields...)
+		return nil
+	}
+	if call.Size >= 0 {
+		fields = append(fields, Field{Key: "bytes", Value: call.Size})
+	}
+	fields = append(fields,
+		Field{Key: "error_code", Value: se.GetCode()},
+		Field{Key: "error", Value: l.redactor.Redact(se.Error())},
+	)
+	if se.GetRequestID() != "" {
+		fields = append(fields, Field{Key: "request_id", Value: se.GetRequestID()})
+	}
+	l.logger.Log(call.Context, l.errorLevel, "storage call failed", fields...)
+	return se
+}
+
+// logOnRead returns the data of call, whose entry is written with the number of bytes
+// read when the data is read to the end or closed, whichever comes first.
+func (l *logger) logOnRead(call *middleware.Call, fields []Field) common.IObjectData {
+	ctx := call.Context
+	return common.NewObjectData(&loggedReader{
+		reader: call.Data.Reader(),
+		done: func(n int64) {
+			l.logger.Log(ctx, l.successLevel, "storage call", append(fields, Field{Key: "bytes", Value: n})...)
+		},
+	})
+}
+
+type loggedReader struct {
+	reader io.ReadCloser
+	n      int64
+	once   sync.Once
+	done   func(n int64)
+}
+
+func (r *loggedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	if err == io.EOF {
+		r.finish()
+	}
+	return n, err
+}
+
+func (r *loggedReader) Close() error {
+	r.finish()
+	return r.reader.Close()
+}
+
+func (r *loggedReader) finish() {
+	r.once.Do(func() {
+		r.done(r.n)
+	})
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type logrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+// NewLogrusLogger returns a Logger writing to a logrus logger or entry.
+func NewLogrusLogger(logger logrus.FieldLogger) Logger {
+	return &logrusLogger{logger: logger}
+}
+
+func (l *logrusLogger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	logrusFields := make(logrus.Fields, len(fields))
+	for _, field := range fields {
+		logrusFields[field.Key] = field.Value
+	}
+	l.logger.WithFields(logrusFields).WithContext(ctx).Log(toLogrusLevel(level), msg)
+}
+
+func toLogrusLevel(level Level) logrus.Level {
+	switch {
+	case level >= LevelError:
+		return logrus.ErrorLevel
+	case level >= LevelWarn:
+		return logrus.WarnLevel
+	case level >= LevelInfo:
+		return logrus.InfoLevel
+	default:
+		return logrus.DebugLevel
+	}
+}
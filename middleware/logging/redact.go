@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// presignedParams matches the credentials and signatures in the query strings of
+// presigned and signed URLs of S3, MinIO and OSS, which may appear in error messages.
+var presignedParams = regexp.MustCompile(`(?i)\b((?:x-amz-(?:signature|credential|security-token)|x-oss-(?:signature|credential|security-token)|signature|ossaccesskeyid|awsaccesskeyid|security-token)=)[^&\s"']*`)
+
+// Redactor removes secrets from the values written to the logs.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// NewRedactor returns a redactor for presigned query strings and the given secrets.
+func NewRedactor(secrets ...string) *Redactor {
+	var oldnew []string
+	for _, secret := range secrets {
+		if secret != "" {
+			oldnew = append(oldnew, secret, redacted)
+		}
+	}
+	return &Redactor{
+		replacer: strings.NewReplacer(oldnew...),
+	}
+}
+
+func (r *Redactor) Redact(value string) string {
+	value = presignedParams.ReplaceAllString(value, "${1}"+redacted)
+	return r.replacer.Replace(value)
+}
@@ -0,0 +1,25 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to a log/slog logger. It requires Go 1.21.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	l.logger.LogAttrs(ctx, slog.Level(level), msg, attrs...)
+}
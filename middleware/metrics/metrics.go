@@ -0,0 +1,145 @@
+// Package metrics records the calls of a common.Storage as Prometheus metrics.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/xuelang-group/go-object-storage/common"
+	"github.com/xuelang-group/go-object-storage/middleware"
+)
+
+const (
+	DirectionRead  = "read"
+	DirectionWrite = "write"
+)
+
+// Options 指标选项
+type Options struct {
+	Namespace string // 指标名前缀，默认 "object_storage"
+	// 耗时直方图的桶（秒），默认 prometheus.DefBuckets
+	Buckets     []float64
+	ConstLabels prometheus.Labels // 附加到所有指标上的固定标签，例如服务名
+}
+
+func (opt *Options) GetNamespace() string {
+	if opt.Namespace == "" {
+		return "object_storage"
+	}
+	return opt.Namespace
+}
+
+func (opt *Options) GetBuckets() []float64 {
+	if len(opt.Buckets) == 0 {
+		return prometheus.DefBuckets
+	}
+	return opt.Buckets
+}
+
+// Collector is a prometheus.Collector for the calls of the storages it wraps. Every metric
+// is labelled with the backend type, the bucket and the operation, and one collector can
+// wrap several storages.
+type Collector struct {
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	bytes      *prometheus.CounterVec
+}
+
+// NewCollector returns a collector, register it with prometheus.MustRegister. A nil options uses the defaults.
+func NewCollector(options *Options) *Collector {
+	if options == nil {
+		options = &Options{}
+	}
+	labels := []string{"backend", "bucket", "operation"}
+	return &Collector{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   options.GetNamespace(),
+			Name:        "operations_total",
+			Help:        "Number of storage operations.",
+			ConstLabels: options.ConstLabels,
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   options.GetNamespace(),
+			Name:        "operation_errors_total",
+			Help:        "Number of failed storage operations by error code.",
+			ConstLabels: options.ConstLabels,
+		}, append(labels, "code")),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   options.GetNamespace(),
+			Name:        "operation_duration_seconds",
+			Help:        "Latency of storage operations.",
+			Buckets:     options.GetBuckets(),
+			ConstLabels: options.ConstLabels,
+		}, labels),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   options.GetNamespace(),
+			Name:        "bytes_total",
+			Help:        "Number of object bytes read from or written to the storage.",
+			ConstLabels: options.ConstLabels,
+		}, append(labels, "direction")),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.operations.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+	c.bytes.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.operations.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+	c.bytes.Collect(ch)
+}
+
+// New returns storage whose calls are recorded by collector.
+func New(storage common.Storage, collector *Collector) *middleware.Storage {
+	return middleware.New(storage, NewInterceptor(collector))
+}
+
+// NewInterceptor returns the interceptor of New, recording on collector. Every error counts as
+// a failure, labelled with its code. Placed before retry in a middleware.Chain it records the
+// total duration of a call, after it every attempt. The bytes of composite operations, such as
+// FPutDir, are recorded by their inner calls.
+func NewInterceptor(collector *Collector) middleware.Interceptor {
+	return collector.intercept
+}
+
+func (c *Collector) intercept(call *middleware.Call, next middleware.Next) common.ObjectStorageError {
+	labels := []string{string(call.Location.Type), call.Bucket, string(call.Operation)}
+
+	if call.Reader != nil {
+		written := c.bytes.WithLabelValues(append(labels, DirectionWrite)...)
+		call.Reader = middleware.HookReader(call.Reader, func(n int) {
+			written.Add(float64(n))
+		})
+	}
+
+	start := time.Now()
+	se := next()
+	c.duration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	c.operations.WithLabelValues(labels...).Inc()
+
+	if se != nil {
+		c.errors.WithLabelValues(append(labels, se.GetCode())...).Inc()
+		return se
+	}
+	switch {
+	case call.Data != nil:
+		read := c.bytes.WithLabelValues(append(labels, DirectionRead)...)
+		call.Data = middleware.HookObjectData(call.Data, func(n int) {
+			read.Add(float64(n))
+		})
+	case call.Reader == nil && call.Size > 0 && !call.Composite:
+		direction := DirectionWrite
+		if call.Operation == middleware.OpFGetObject {
+			direction = DirectionRead
+		}
+		c.bytes.WithLabelValues(append(labels, direction)...).Add(float64(call.Size))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package middleware wraps a common.Storage so that every call passes through an
+// Interceptor. The decorators in the sub packages, such as retry, are built on it.
+package middleware
+
+import (
+	"context"
+	"io"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+// Operation is the name of the intercepted Storage method.
+type Operation string
+
+const (
+	OpCreateBucket          Operation = "CreateBucket"
+	OpBucketExists          Operation = "BucketExists"
+	OpEnsureBucket          Operation = "EnsureBucket"
+	OpEnableVersioning      Operation = "EnableVersioning"
+	OpSuspendVersioning     Operation = "SuspendVersioning"
+	OpGetVersioningStatus   Operation = "GetVersioningStatus"
+	OpGetBucketLifecycle    Operation = "GetBucketLifecycle"
+	OpPutBucketLifecycle    Operation = "PutBucketLifecycle"
+	OpDeleteBucketLifecycle Operation = "DeleteBucketLifecycle"
+	OpObjectExist           Operation = "ObjectExist"
+	OpStatObject            Operation = "StatObject"
+	OpGetObject             Operation = "GetObject"
+	OpFGetObject            Operation = "FGetObject"
+	OpFPutObject            Operation = "FPutObject"
+	OpFGetDir               Operation = "FGetDir"
+	OpFPutDir               Operation = "FPutDir"
+	OpPutObject             Operation = "PutObject"
+	OpDeleteObject          Operation = "DeleteObject"
+	OpDeleteObjects         Operation = "DeleteObjects"
+	OpDeletePrefix          Operation = "DeletePrefix"
+	OpListObjects           Operation = "ListObjects"
+	OpWalkObjects           Operation = "WalkObjects"
+	OpListPage              Operation = "ListPage"
+	OpCopyObject            Operation = "CopyObject"
+	OpCopyObjectFromBucket  Operation = "CopyObjectFromBucket"
+	OpMoveObject            Operation = "MoveObject"
+	OpGetObjectTags         Operation = "GetObjectTags"
+	OpPutObjectTags         Operation = "PutObjectTags"
+	OpDeleteObjectTags      Operation = "DeleteObjectTags"
+	OpListObjectVersions    Operation = "ListObjectVersions"
+	OpGetObjectVersion      Operation = "GetObjectVersion"
+	OpDeleteObjectVersion   Operation = "DeleteObjectVersion"
+	OpRestoreObjectVersion  Operation = "RestoreObjectVersion"
+	OpCopyDir               Operation = "CopyDir"
+	OpMoveDir               Operation = "MoveDir"
+	OpMkDir                 Operation = "MkDir"
+	OpIsDir                 Operation = "IsDir"
+	OpRemoveDir             Operation = "RemoveDir"
+	OpSync                  Operation = "Sync"
+)
+
+// Call describes one intercepted Storage call.
+type Call struct {
+	// Context 调用方通过 WithContext 绑定的上下文，未绑定时为 context.Background()。
+	// 拦截器可以在调用 next 前替换它，例如加入 span，被包装的 Storage 和组合操作的内部调用会使用替换后的上下文
+	Context   context.Context
+	Operation Operation
+	Location  common.StorageLocation // 被包装的 Storage 所在的服务和存储桶
+	Bucket    string                 // 调用操作的存储桶，对象操作为 Location.BucketName
+	Key       string                 // 调用操作的对象键、前缀或目录，没有时为空
+
+	// 重复执行与执行一次效果相同的调用，可以安全重试。
+	// 对于 WalkObjects，一旦有一页交给了调用方就不再成立
+	Idempotent bool
+	// 由多个 Storage 调用组合而成的操作，例如 CopyDir，其内部的调用同样会被拦截
+	Composite bool
+
+	// PutObject 和 PutObjectWithOptions 上传的数据。拦截器可以在调用 next 前替换它，
+	// 后端读取的是替换后的 Reader
+	Reader io.Reader
+	// 调用写入或读取的字节数，未知时为 -1。上传在调用前已知，FGetObject 在调用成功后填充
+	Size int64
+	// GetObject 和 GetObjectVersion 返回的对象数据，在 next 返回后填充，拦截器可以替换它
+	Data common.IObjectData
+
+	readHooks []ReadHook
+}
+
+// AddReadHook registers hook to be called after every Read of the object data the call
+// transfers: the reader of PutObject, the data returned by GetObject and GetObjectVersion,
+// and the local file of FPutObject and FGetObject. Local files with hooks are streamed
+// through PutObjectWithOptions and GetObject instead of the file API of the backend.
+// Hooks must be added before calling next. In a Chain, the hooks added by an interceptor
+// are dropped when it returns, so an interceptor run once per retry attempt adds them once
+// per attempt instead of stacking them.
+func (c *Call) AddReadHook(hook ReadHook) {
+	c.readHooks = append(c.readHooks, hook)
+}
+
+// Next performs the intercepted call, or the next interceptor of a chain.
+type Next func() common.ObjectStorageError
+
+// Interceptor wraps every call of the Storage returned by New.
+// It may call next several times, for example to retry the call.
+type Interceptor func(call *Call, next Next) common.ObjectStorageError
+
+// Chain combines interceptors into one, the first one being the outermost.
+func Chain(interceptors ...Interceptor) Interceptor {
+	return func(call *Call, next Next) common.ObjectStorageError {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func() common.ObjectStorageError {
+				hooks := len(call.readHooks)
+				se := interceptor(call, inner)
+				call.readHooks = call.readHooks[:hooks]
+				return se
+			}
+		}
+		return next()
+	}
+}
+
+// ContextBinder is implemented by storages that can bind the context of the caller, see WithContext.
+type ContextBinder interface {
+	WithContext(ctx context.Context) common.Storage
+}
+
+// WithContext returns storage bound to ctx if it supports it, or storage itself otherwise.
+// The calls of the returned storage carry ctx, which links them to the traces of the caller.
+func WithContext(storage common.Storage, ctx context.Context) common.Storage {
+	if binder, ok := storage.(ContextBinder); ok {
+		return binder.WithContext(ctx)
+	}
+	return storage
+}
@@ -0,0 +1,150 @@
+// Package ratelimit limits the operations per second and the bandwidth of a common.Storage.
+package ratelimit
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"github.com/xuelang-group/go-object-storage/common"
+	"github.com/xuelang-group/go-object-storage/middleware"
+)
+
+// Limits 一组限制，0 表示不限制
+type Limits struct {
+	OpsPerSecond        float64 // 每秒操作数，组合操作（例如 CopyDir）不计入，其内部的每次调用计入
+	ReadBytesPerSecond  int     // 读取带宽，作用于 GetObject、GetObjectVersion 和 FGetObject
+	WriteBytesPerSecond int     // 写入带宽，作用于 PutObject、PutObjectWithOptions 和 FPutObject
+}
+
+// Options 限流选项
+type Options struct {
+	Global Limits // 全局限制，所有调用共享
+	// 按对象键前缀限制，调用只受最长匹配前缀的限制，同时受 Global 限制
+	Prefixes map[string]Limits
+}
+
+// New returns storage whose calls are limited according to options. Byte limits wrap the
+// readers passed to PutObject and returned by GetObject, local files are streamed through
+// them as well. A nil options does not limit anything.
+func New(storage common.Storage, options *Options) *middleware.Storage {
+	return middleware.New(storage, NewInterceptor(options))
+}
+
+// NewInterceptor returns the interceptor of New. Composite operations are not limited
+// themselves, their inner calls are. Place it after retry in a middleware.Chain, so that
+// the retried attempts are limited too.
+func NewInterceptor(options *Options) middleware.Interceptor {
+	if options == nil {
+		options = &Options{}
+	}
+	l := &limiter{
+		global:   newLimiters(options.Global),
+		prefixes: make(map[string]*limiters, len(options.Prefixes)),
+	}
+	for prefix, limits := range options.Prefixes {
+		l.prefixes[prefix] = newLimiters(limits)
+	}
+	return l.intercept
+}
+
+type limiters struct {
+	ops   *rate.Limiter
+	read  *rate.Limiter
+	write *rate.Limiter
+}
+
+func newLimiters(limits Limits) *limiters {
+	l := &limiters{}
+	if limits.OpsPerSecond > 0 {
+		burst := int(limits.OpsPerSecond)
+		if burst < 1 {
+			burst = 1
+		}
+		l.ops = rate.NewLimiter(rate.Limit(limits.OpsPerSecond), burst)
+	}
+	// the burst of the byte limiters is one second of bandwidth
+	if limits.ReadBytesPerSecond > 0 {
+		l.read = rate.NewLimiter(rate.Limit(limits.ReadBytesPerSecond), limits.ReadBytesPerSecond)
+	}
+	if limits.WriteBytesPerSecond > 0 {
+		l.write = rate.NewLimiter(rate.Limit(limits.WriteBytesPerSecond), limits.WriteBytesPerSecond)
+	}
+	return l
+}
+
+type limiter struct {
+	global   *limiters
+	prefixes map[string]*limiters
+}
+
+func (l *limiter) intercept(call *middleware.Call, next middleware.Next) common.ObjectStorageError {
+	if call.Composite {
+		return next()
+	}
+
+	applied := []*limiters{l.global}
+	if prefixed := l.match(call.Key); prefixed != nil {
+		applied = append(applied, prefixed)
+	}
+
+	for _, limiters := range applied {
+		if limiters.ops == nil {
+			continue
+		}
+		if err := limiters.ops.Wait(call.Context); err != nil {
+			return common.NewStorageError(call.Location.Type, common.ErrCodeRequestTimeout, "rate limit: "+err.Error(), err)
+		}
+	}
+
+	var bandwidth []*rate.Limiter
+	for _, limiters := range applied {
+		switch call.Operation {
+		case middleware.OpGetObject, middleware.OpGetObjectVersion, middleware.OpFGetObject:
+			if limiters.read != nil {
+				bandwidth = append(bandwidth, limiters.read)
+			}
+		case middleware.OpPutObject, middleware.OpFPutObject:
+			if limiters.write != nil {
+				bandwidth = append(bandwidth, limiters.write)
+			}
+		}
+	}
+	if len(bandwidth) > 0 {
+		ctx := call.Context
+		call.AddReadHook(func(n int) {
+			for _, limiter := range bandwidth {
+				waitN(ctx, limiter, n)
+			}
+		})
+	}
+	return next()
+}
+
+// match returns the limiters of the longest prefix of key, or nil if none matches.
+func (l *limiter) match(key string) *limiters {
+	var matched *limiters
+	longest := -1
+	for prefix, limiters := range l.prefixes {
+		if len(prefix) > longest && strings.HasPrefix(key, prefix) {
+			matched, longest = limiters, len(prefix)
+		}
+	}
+	return matched
+}
+
+// waitN waits for n bytes in chunks of at most the burst of limiter. It stops waiting
+// once ctx is done, the read itself is not failed.
+func waitN(ctx context.Context, limiter *rate.Limiter, n int) {
+	for n > 0 {
+		chunk := n
+		if chunk > limiter.Burst() {
+			chunk = limiter.Burst()
+		}
+		if limiter.WaitN(ctx, chunk) != nil {
+			return
+		}
+		n -= chunk
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"io"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+// ReadHook is called after every Read of a hooked reader with the number of bytes read.
+// It may block, for example to throttle the reader.
+type ReadHook func(n int)
+
+// HookReader returns a reader that calls hook after every Read of reader. The returned
+// reader implements io.Seeker if reader does, so that it can still be rewound on retries.
+func HookReader(reader io.Reader, hook ReadHook) io.Reader {
+	hooked := &hookedReader{reader: reader, hook: hook}
+	if seeker, ok := reader.(io.Seeker); ok {
+		return &hookedReadSeeker{hookedReader: hooked, seeker: seeker}
+	}
+	return hooked
+}
+
+// HookObjectData returns data whose reader calls hook after every Read.
+func HookObjectData(data common.IObjectData, hook ReadHook) common.IObjectData {
+	reader := data.Reader()
+	return common.NewObjectData(&hookedReadCloser{
+		hookedReader: &hookedReader{reader: reader, hook: hook},
+		closer:       reader,
+	})
+}
+
+func (c *Call) hookReader(reader io.Reader) io.Reader {
+	if len(c.readHooks) == 0 {
+		return reader
+	}
+	return HookReader(reader, c.readHook())
+}
+
+func (c *Call) hookData(data common.IObjectData) common.IObjectData {
+	if len(c.readHooks) == 0 || data == nil {
+		return data
+	}
+	return HookObjectData(data, c.readHook())
+}
+
+// readHook combines the current read hooks of the call. The hooks are copied because the
+// interceptors that added them drop them when they return, see Chain.
+func (c *Call) readHook() ReadHook {
+	hooks := append([]ReadHook(nil), c.readHooks...)
+	return func(n int) {
+		for _, hook := range hooks {
+			hook(n)
+		}
+	}
+}
+
+type hookedReader struct {
+	reader io.Reader
+	hook   ReadHook
+}
+
+func (r *hookedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.hook(n)
+	}
+	return n, err
+}
+
+type hookedReadSeeker struct {
+	*hookedReader
+	seeker io.Seeker
+}
+
+func (r *hookedReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.seeker.Seek(offset, whence)
+}
+
+type hookedReadCloser struct {
+	*hookedReader
+	closer io.Closer
+}
+
+func (r *hookedReadCloser) Close() error {
+	return r.closer.Close()
+}
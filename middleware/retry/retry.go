@@ -0,0 +1,218 @@
+// Package retry retries the transient failures of a common.Storage.
+package retry
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/xuelang-group/go-object-storage/common"
+	"github.com/xuelang-group/go-object-storage/middleware"
+)
+
+// Policy 重试策略
+type Policy struct {
+	MaxAttempts    int           // 最大尝试次数，包含第一次调用，默认 3
+	InitialBackoff time.Duration // 第一次重试前的等待时间，默认 100ms
+	MaxBackoff     time.Duration // 等待时间上限，默认 10s
+	Multiplier     float64       // 每次重试等待时间的增长倍数，默认 2
+	// 随机抖动比例，取值 0~1，等待时间在 [backoff*(1-Jitter), backoff] 之间随机。
+	// 默认 0.5，小于 0 表示不抖动
+	Jitter float64
+
+	// 每种操作的重试预算，为 nil 时不限制
+	Budget *Budget
+	// 判断错误是否可以重试，默认使用错误的 IsRetryable
+	Retryable func(se common.ObjectStorageError) bool
+}
+
+// Budget 每种操作的重试预算。每种操作有一个令牌桶，初始为 MaxTokens，
+// 每次可重试的失败消耗 1 个令牌，每次成功归还 TokenRatio 个令牌，令牌不超过 MaxTokens / 2 时停止重试，
+// 这样在后端持续故障时重试不会成倍放大请求量
+type Budget struct {
+	MaxTokens  float64 // 令牌上限，默认 10
+	TokenRatio float64 // 每次成功调用归还的令牌数，默认 0.1
+}
+
+func (p *Policy) GetMaxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return 3
+	}
+	return p.MaxAttempts
+}
+
+func (p *Policy) GetInitialBackoff() time.Duration {
+	if p.InitialBackoff <= 0 {
+		return 100 * time.Millisecond
+	}
+	return p.InitialBackoff
+}
+
+func (p *Policy) GetMaxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return 10 * time.Second
+	}
+	return p.MaxBackoff
+}
+
+func (p *Policy) GetMultiplier() float64 {
+	if p.Multiplier < 1 {
+		return 2
+	}
+	return p.Multiplier
+}
+
+func (p *Policy) GetJitter() float64 {
+	if p.Jitter < 0 {
+		return 0
+	}
+	if p.Jitter == 0 {
+		return 0.5
+	}
+	return math.Min(p.Jitter, 1)
+}
+
+func (p *Policy) IsRetryable(se common.ObjectStorageError) bool {
+	if p.Retryable != nil {
+		return p.Retryable(se)
+	}
+	return se.IsRetryable()
+}
+
+// Backoff returns the time to wait before the given retry, starting at 1.
+func (p *Policy) Backoff(retry int) time.Duration {
+	backoff := float64(p.GetInitialBackoff()) * math.Pow(p.GetMultiplier(), float64(retry-1))
+	backoff = math.Min(backoff, float64(p.GetMaxBackoff()))
+	backoff -= backoff * p.GetJitter() * rand.Float64()
+	return time.Duration(backoff)
+}
+
+func (b *Budget) GetMaxTokens() float64 {
+	if b.MaxTokens <= 0 {
+		return 10
+	}
+	return b.MaxTokens
+}
+
+func (b *Budget) GetTokenRatio() float64 {
+	if b.TokenRatio <= 0 {
+		return 0.1
+	}
+	return b.TokenRatio
+}
+
+// New returns storage whose idempotent calls are retried according to policy.
+// A nil policy uses the defaults.
+func New(storage common.Storage, policy *Policy) *middleware.Storage {
+	return middleware.New(storage, NewInterceptor(policy))
+}
+
+// NewInterceptor returns the interceptor of New. Only idempotent calls are retried, and only
+// on the errors accepted by policy; composite operations are retried through their inner calls.
+// In a middleware.Chain, interceptors placed before it see each call once, those after it see
+// every attempt, so a circuit breaker belongs after it.
+func NewInterceptor(policy *Policy) middleware.Interceptor {
+	if policy == nil {
+		policy = &Policy{}
+	}
+	r := &retrier{
+		policy: policy,
+		tokens: make(map[middleware.Operation]float64),
+	}
+	return r.intercept
+}
+
+type retrier struct {
+	policy *Policy
+
+	mu     sync.Mutex
+	tokens map[middleware.Operation]float64
+}
+
+func (r *retrier) intercept(call *middleware.Call, next middleware.Next) common.ObjectStorageError {
+	// composite operations are not retried as a whole, their inner calls are
+	if call.Composite {
+		return next()
+	}
+
+	reader := call.Reader
+	offset, rewindable := readerOffset(reader)
+
+	var se common.ObjectStorageError
+	for attempt := 1; ; attempt++ {
+		call.Reader = reader
+		se = next()
+		// failures that are not retried, such as NoSuchKey, do not spend the budget
+		retryable := se != nil && r.policy.IsRetryable(se)
+		if se == nil || retryable {
+			r.record(call.Operation, se == nil)
+		}
+		if se == nil || attempt >= r.policy.GetMaxAttempts() || !call.Idempotent || !retryable {
+			return se
+		}
+		if reader != nil && (!rewindable || rewind(reader, offset) != nil) {
+			return se
+		}
+		if !r.allowRetry(call.Operation) {
+			return se
+		}
+
+		timer := time.NewTimer(r.policy.Backoff(attempt))
+		select {
+		case <-call.Context.Done():
+			timer.Stop()
+			return se
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *retrier) record(op middleware.Operation, success bool) {
+	budget := r.policy.Budget
+	if budget == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tokens, ok := r.tokens[op]
+	if !ok {
+		tokens = budget.GetMaxTokens()
+	}
+	if success {
+		tokens = math.Min(tokens+budget.GetTokenRatio(), budget.GetMaxTokens())
+	} else {
+		tokens = math.Max(tokens-1, 0)
+	}
+	r.tokens[op] = tokens
+}
+
+// allowRetry reports whether the budget of op still allows a retry.
+func (r *retrier) allowRetry(op middleware.Operation) bool {
+	budget := r.policy.Budget
+	if budget == nil {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tokens, ok := r.tokens[op]
+	return !ok || tokens > budget.GetMaxTokens()/2
+}
+
+// readerOffset returns the current offset of reader if it can be rewound.
+func readerOffset(reader io.Reader) (int64, bool) {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	return offset, err == nil
+}
+
+func rewind(reader io.Reader, offset int64) error {
+	_, err := reader.(io.Seeker).Seek(offset, io.SeekStart)
+	return err
+}
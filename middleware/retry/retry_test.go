@@ -0,0 +1,40 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xuelang-group/go-object-storage/common"
+	"github.com/xuelang-group/go-object-storage/middleware"
+)
+
+func TestBudgetIgnoresNonRetryableFailures(t *testing.T) {
+	intercept := NewInterceptor(&Policy{
+		MaxAttempts:    2,
+		InitialBackoff: 1,
+		Budget:         &Budget{MaxTokens: 4},
+	})
+	call := func(code string) int {
+		calls := 0
+		intercept(&middleware.Call{Context: context.Background(), Operation: middleware.OpGetObject, Idempotent: true}, func() common.ObjectStorageError {
+			calls++
+			return common.NewStorageError(common.MINIO, code, code, errors.New(code))
+		})
+		return calls
+	}
+
+	// not found failures are not retried and must not exhaust the budget
+	for i := 0; i < 10; i++ {
+		if calls := call(common.ErrCodeNoSuchKey); calls != 1 {
+			t.Fatalf("NoSuchKey called %d times, want 1", calls)
+		}
+	}
+	if calls := call(common.ErrCodeServiceUnavailable); calls != 2 {
+		t.Errorf("ServiceUnavailable called %d times, want 2", calls)
+	}
+	// the budget is spent by the retryable failures only
+	if calls := call(common.ErrCodeServiceUnavailable); calls != 1 {
+		t.Errorf("ServiceUnavailable called %d times after exhausting the budget, want 1", calls)
+	}
+}
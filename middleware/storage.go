@@ -0,0 +1,479 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+// Storage forwards every method to the wrapped storage through an Interceptor.
+// Operations built on other Storage methods, such as CopyDir, run the shared
+// helpers of common on the wrapper, so their inner calls are intercepted too.
+type Storage struct {
+	storage   common.Storage
+	intercept Interceptor
+	ctx       context.Context
+}
+
+func New(storage common.Storage, intercept Interceptor) *Storage {
+	return &Storage{
+		storage:   storage,
+		intercept: intercept,
+		ctx:       context.Background(),
+	}
+}
+
+// Unwrap returns the wrapped storage.
+func (s *Storage) Unwrap() common.Storage {
+	return s.storage
+}
+
+// WithContext returns a copy of the storage whose calls carry ctx. The context is
+// bound to the wrapped storage as well if it supports it.
+func (s *Storage) WithContext(ctx context.Context) common.Storage {
+	return &Storage{
+		storage:   WithContext(s.storage, ctx),
+		intercept: s.intercept,
+		ctx:       ctx,
+	}
+}
+
+// inner returns the wrapped storage bound to the context of call, which interceptors may replace.
+func (s *Storage) inner(call *Call) common.Storage {
+	if call.Context == s.ctx {
+		return s.storage
+	}
+	return WithContext(s.storage, call.Context)
+}
+
+// bound returns the storage the inner calls of a composite call are made on.
+func (s *Storage) bound(call *Call) common.Storage {
+	if call.Context == s.ctx {
+		return s
+	}
+	return s.WithContext(call.Context)
+}
+
+func (s *Storage) newCall(op Operation, key string) *Call {
+	location := s.storage.GetLocation()
+	return &Call{
+		Context:   s.ctx,
+		Operation: op,
+		Location:  location,
+		Bucket:    location.BucketName,
+		Key:       key,
+		Size:      -1,
+	}
+}
+
+func (s *Storage) newBucketCall(op Operation, bucketName string, idempotent bool) *Call {
+	call := s.newCall(op, "")
+	call.Bucket = bucketName
+	call.Idempotent = idempotent
+	return call
+}
+
+func (s *Storage) newCompositeCall(op Operation, key string) *Call {
+	call := s.newCall(op, key)
+	call.Composite = true
+	return call
+}
+
+func (s *Storage) provider() common.BackendType {
+	return s.storage.GetLocation().Type
+}
+
+func (s *Storage) GetLocation() common.StorageLocation {
+	return s.storage.GetLocation()
+}
+
+func (s *Storage) CreateBucket(bucketName string) common.ObjectStorageError {
+	call := s.newBucketCall(OpCreateBucket, bucketName, false)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).CreateBucket(bucketName)
+	})
+}
+
+func (s *Storage) BucketExists(bucketName string) (exist bool, se common.ObjectStorageError) {
+	call := s.newBucketCall(OpBucketExists, bucketName, true)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		exist, se = s.inner(call).BucketExists(bucketName)
+		return se
+	})
+	return exist, se
+}
+
+func (s *Storage) EnsureBucket(bucketName string) common.ObjectStorageError {
+	call := s.newBucketCall(OpEnsureBucket, bucketName, true)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).EnsureBucket(bucketName)
+	})
+}
+
+func (s *Storage) EnableVersioning(bucketName string) common.ObjectStorageError {
+	call := s.newBucketCall(OpEnableVersioning, bucketName, true)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).EnableVersioning(bucketName)
+	})
+}
+
+func (s *Storage) SuspendVersioning(bucketName string) common.ObjectStorageError {
+	call := s.newBucketCall(OpSuspendVersioning, bucketName, true)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).SuspendVersioning(bucketName)
+	})
+}
+
+func (s *Storage) GetVersioningStatus(bucketName string) (status common.VersioningStatus, se common.ObjectStorageError) {
+	call := s.newBucketCall(OpGetVersioningStatus, bucketName, true)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		status, se = s.inner(call).GetVersioningStatus(bucketName)
+		return se
+	})
+	return status, se
+}
+
+func (s *Storage) GetBucketLifecycle(bucketName string) (config *common.LifecycleConfiguration, se common.ObjectStorageError) {
+	call := s.newBucketCall(OpGetBucketLifecycle, bucketName, true)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		config, se = s.inner(call).GetBucketLifecycle(bucketName)
+		return se
+	})
+	return config, se
+}
+
+func (s *Storage) PutBucketLifecycle(bucketName string, config *common.LifecycleConfiguration) common.ObjectStorageError {
+	call := s.newBucketCall(OpPutBucketLifecycle, bucketName, true)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).PutBucketLifecycle(bucketName, config)
+	})
+}
+
+func (s *Storage) DeleteBucketLifecycle(bucketName string) common.ObjectStorageError {
+	call := s.newBucketCall(OpDeleteBucketLifecycle, bucketName, true)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).DeleteBucketLifecycle(bucketName)
+	})
+}
+
+func (s *Storage) ObjectExist(objectKey string) (exist bool, se common.ObjectStorageError) {
+	call := s.newCall(OpObjectExist, objectKey)
+	call.Idempotent = true
+	se = s.intercept(call, func() common.ObjectStorageError {
+		exist, se = s.inner(call).ObjectExist(objectKey)
+		return se
+	})
+	return exist, se
+}
+
+func (s *Storage) StatObject(objectKey string) (info *common.ObjectInfo, se common.ObjectStorageError) {
+	call := s.newCall(OpStatObject, objectKey)
+	call.Idempotent = true
+	se = s.intercept(call, func() common.ObjectStorageError {
+		info, se = s.inner(call).StatObject(objectKey)
+		return se
+	})
+	return info, se
+}
+
+func (s *Storage) GetObject(objectKey string) (common.IObjectData, common.ObjectStorageError) {
+	call := s.newCall(OpGetObject, objectKey)
+	call.Idempotent = true
+	se := s.intercept(call, func() (se common.ObjectStorageError) {
+		data, se := s.inner(call).GetObject(objectKey)
+		call.Data = call.hookData(data)
+		return se
+	})
+	if se != nil {
+		return nil, se
+	}
+	return call.Data, nil
+}
+
+func (s *Storage) FGetObject(objectKey, localFilePath string) common.ObjectStorageError {
+	call := s.newCall(OpFGetObject, objectKey)
+	call.Idempotent = true
+	return s.intercept(call, func() common.ObjectStorageError {
+		var se common.ObjectStorageError
+		if len(call.readHooks) > 0 {
+			se = s.getLocalFile(call, objectKey, localFilePath)
+		} else {
+			se = s.inner(call).FGetObject(objectKey, localFilePath)
+		}
+		if se == nil {
+			call.Size = localFileSize(localFilePath)
+		}
+		return se
+	})
+}
+
+func (s *Storage) FPutObject(localFilePath, objectKey string) common.ObjectStorageError {
+	call := s.newCall(OpFPutObject, objectKey)
+	call.Idempotent = true
+	call.Size = localFileSize(localFilePath)
+	return s.intercept(call, func() common.ObjectStorageError {
+		if len(call.readHooks) > 0 {
+			return s.putLocalFile(call, localFilePath, objectKey, nil)
+		}
+		return s.inner(call).FPutObject(localFilePath, objectKey)
+	})
+}
+
+func (s *Storage) FGetDir(prefix, localDir string, options *common.LocalDirOptions) (result *common.DirTransferResult, se common.ObjectStorageError) {
+	call := s.newCompositeCall(OpFGetDir, prefix)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		result, se = common.FGetDir(s.bound(call), s.provider(), prefix, localDir, options)
+		return se
+	})
+	return result, se
+}
+
+func (s *Storage) FPutDir(localDir, prefix string, options *common.LocalDirOptions) (result *common.DirTransferResult, se common.ObjectStorageError) {
+	call := s.newCompositeCall(OpFPutDir, prefix)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		result, se = common.FPutDir(s.bound(call), s.provider(), localDir, prefix, options)
+		return se
+	})
+	return result, se
+}
+
+func (s *Storage) PutObject(objectKey string, reader io.Reader) common.ObjectStorageError {
+	call := s.newCall(OpPutObject, objectKey)
+	call.Idempotent = true
+	call.Reader = reader
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).PutObject(objectKey, call.hookReader(call.Reader))
+	})
+}
+
+func (s *Storage) PutObjectWithOptions(objectKey string, reader io.Reader, options *common.PutOptions) common.ObjectStorageError {
+	call := s.newCall(OpPutObject, objectKey)
+	call.Idempotent = true
+	call.Reader = reader
+	if options != nil && options.Size > 0 {
+		call.Size = options.Size
+	}
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).PutObjectWithOptions(objectKey, call.hookReader(call.Reader), options)
+	})
+}
+
+func (s *Storage) FPutObjectWithOptions(localFilePath, objectKey string, options *common.PutOptions) common.ObjectStorageError {
+	call := s.newCall(OpFPutObject, objectKey)
+	call.Idempotent = true
+	call.Size = localFileSize(localFilePath)
+	return s.intercept(call, func() common.ObjectStorageError {
+		if len(call.readHooks) > 0 {
+			return s.putLocalFile(call, localFilePath, objectKey, options)
+		}
+		return s.inner(call).FPutObjectWithOptions(localFilePath, objectKey, options)
+	})
+}
+
+func (s *Storage) DeleteObject(objectKey string) common.ObjectStorageError {
+	// DeleteObject fails with NoSuchKey once the object is gone, so it is not idempotent
+	call := s.newCall(OpDeleteObject, objectKey)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).DeleteObject(objectKey)
+	})
+}
+
+func (s *Storage) DeleteObjects(objectKeys []string) (result *common.DeleteObjectsResult, se common.ObjectStorageError) {
+	call := s.newCall(OpDeleteObjects, "")
+	call.Idempotent = true
+	se = s.intercept(call, func() common.ObjectStorageError {
+		result, se = s.inner(call).DeleteObjects(objectKeys)
+		return se
+	})
+	return result, se
+}
+
+func (s *Storage) DeletePrefix(prefix string, options *common.DeletePrefixOptions) (result *common.DeleteObjectsResult, se common.ObjectStorageError) {
+	call := s.newCompositeCall(OpDeletePrefix, prefix)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		result, se = common.DeletePrefix(s.bound(call), s.provider(), prefix, options)
+		return se
+	})
+	return result, se
+}
+
+func (s *Storage) ListObjects(options common.ListOptions) (objects []common.ObjectInfo, se common.ObjectStorageError) {
+	call := s.newCall(OpListObjects, options.ObjectKeyPrefix)
+	call.Idempotent = true
+	se = s.intercept(call, func() common.ObjectStorageError {
+		objects, se = s.inner(call).ListObjects(options)
+		return se
+	})
+	return objects, se
+}
+
+func (s *Storage) WalkObjects(options common.ListOptions, fn common.ObjectPageFunc) common.ObjectStorageError {
+	call := s.newCall(OpWalkObjects, options.ObjectKeyPrefix)
+	call.Idempotent = true
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).WalkObjects(options, func(objects []common.ObjectInfo) bool {
+			// the pages already handed over would be repeated by another attempt
+			call.Idempotent = false
+			return fn(objects)
+		})
+	})
+}
+
+func (s *Storage) ListPage(options common.ListOptions, pageToken string) (objects []common.ObjectInfo, nextToken string, se common.ObjectStorageError) {
+	call := s.newCall(OpListPage, options.ObjectKeyPrefix)
+	call.Idempotent = true
+	se = s.intercept(call, func() common.ObjectStorageError {
+		objects, nextToken, se = s.inner(call).ListPage(options, pageToken)
+		return se
+	})
+	return objects, nextToken, se
+}
+
+func (s *Storage) CopyObject(srcObjectKey, destObjectKey string, options *common.CopyOptions) common.ObjectStorageError {
+	call := s.newCall(OpCopyObject, destObjectKey)
+	call.Idempotent = options != nil && options.Overwrite
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).CopyObject(srcObjectKey, destObjectKey, options)
+	})
+}
+
+func (s *Storage) CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey string, options *common.CopyOptions) common.ObjectStorageError {
+	call := s.newCall(OpCopyObjectFromBucket, destObjectKey)
+	call.Idempotent = options != nil && options.Overwrite
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey, options)
+	})
+}
+
+func (s *Storage) MoveObject(srcObjectKey, destObjectKey string, options *common.MoveOptions) common.ObjectStorageError {
+	call := s.newCall(OpMoveObject, destObjectKey)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).MoveObject(srcObjectKey, destObjectKey, options)
+	})
+}
+
+func (s *Storage) GetObjectTags(objectKey string) (tags map[string]string, se common.ObjectStorageError) {
+	call := s.newCall(OpGetObjectTags, objectKey)
+	call.Idempotent = true
+	se = s.intercept(call, func() common.ObjectStorageError {
+		tags, se = s.inner(call).GetObjectTags(objectKey)
+		return se
+	})
+	return tags, se
+}
+
+func (s *Storage) PutObjectTags(objectKey string, tags map[string]string) common.ObjectStorageError {
+	call := s.newCall(OpPutObjectTags, objectKey)
+	call.Idempotent = true
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).PutObjectTags(objectKey, tags)
+	})
+}
+
+func (s *Storage) DeleteObjectTags(objectKey string) common.ObjectStorageError {
+	call := s.newCall(OpDeleteObjectTags, objectKey)
+	call.Idempotent = true
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).DeleteObjectTags(objectKey)
+	})
+}
+
+func (s *Storage) ListObjectVersions(options common.ListOptions) (objects []common.ObjectInfo, se common.ObjectStorageError) {
+	call := s.newCall(OpListObjectVersions, options.ObjectKeyPrefix)
+	call.Idempotent = true
+	se = s.intercept(call, func() common.ObjectStorageError {
+		objects, se = s.inner(call).ListObjectVersions(options)
+		return se
+	})
+	return objects, se
+}
+
+func (s *Storage) GetObjectVersion(objectKey, versionID string) (common.IObjectData, common.ObjectStorageError) {
+	call := s.newCall(OpGetObjectVersion, objectKey)
+	call.Idempotent = true
+	se := s.intercept(call, func() (se common.ObjectStorageError) {
+		data, se := s.inner(call).GetObjectVersion(objectKey, versionID)
+		call.Data = call.hookData(data)
+		return se
+	})
+	if se != nil {
+		return nil, se
+	}
+	return call.Data, nil
+}
+
+func (s *Storage) DeleteObjectVersion(objectKey, versionID string) common.ObjectStorageError {
+	call := s.newCall(OpDeleteObjectVersion, objectKey)
+	call.Idempotent = true
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).DeleteObjectVersion(objectKey, versionID)
+	})
+}
+
+func (s *Storage) RestoreObjectVersion(objectKey, versionID string) common.ObjectStorageError {
+	call := s.newCall(OpRestoreObjectVersion, objectKey)
+	call.Idempotent = true
+	return s.intercept(call, func() common.ObjectStorageError {
+		return s.inner(call).RestoreObjectVersion(objectKey, versionID)
+	})
+}
+
+func (s *Storage) CopyDir(srcDirPath, destDirPath string, options *common.CopyDirOptions) (result *common.DirTransferResult, se common.ObjectStorageError) {
+	call := s.newCompositeCall(OpCopyDir, destDirPath)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		result, se = common.CopyDir(s.bound(call), s.provider(), srcDirPath, destDirPath, options)
+		return se
+	})
+	return result, se
+}
+
+func (s *Storage) MoveDir(srcDirPath, destDirPath string, options *common.MoveDirOptions) (result *common.DirTransferResult, se common.ObjectStorageError) {
+	call := s.newCompositeCall(OpMoveDir, destDirPath)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		result, se = common.MoveDir(s.bound(call), s.provider(), srcDirPath, destDirPath, options)
+		return se
+	})
+	return result, se
+}
+
+func (s *Storage) MkDir(dirPath string) common.ObjectStorageError {
+	call := s.newCompositeCall(OpMkDir, dirPath)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return common.MkDir(s.bound(call), s.provider(), dirPath)
+	})
+}
+
+func (s *Storage) IsDir(dirPath string) (isDir bool, se common.ObjectStorageError) {
+	call := s.newCompositeCall(OpIsDir, dirPath)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		isDir, se = common.IsDir(s.bound(call), dirPath)
+		return se
+	})
+	return isDir, se
+}
+
+func (s *Storage) RemoveDir(dirPath string, recursive bool) common.ObjectStorageError {
+	call := s.newCompositeCall(OpRemoveDir, dirPath)
+	return s.intercept(call, func() common.ObjectStorageError {
+		return common.RemoveDir(s.bound(call), s.provider(), dirPath, recursive)
+	})
+}
+
+func (s *Storage) Sync(localDir, prefix string, options *common.SyncOptions) (result *common.SyncResult, se common.ObjectStorageError) {
+	call := s.newCompositeCall(OpSync, prefix)
+	se = s.intercept(call, func() common.ObjectStorageError {
+		result, se = common.Sync(s.bound(call), s.provider(), localDir, prefix, options)
+		return se
+	})
+	return result, se
+}
+
+func localFileSize(localFilePath string) int64 {
+	info, err := os.Stat(localFilePath)
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
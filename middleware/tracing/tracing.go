@@ -0,0 +1,109 @@
+// Package tracing creates an OpenTelemetry span for every call of a common.Storage.
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/xuelang-group/go-object-storage/common"
+	"github.com/xuelang-group/go-object-storage/middleware"
+)
+
+const instrumentationName = "github.com/xuelang-group/go-object-storage/middleware/tracing"
+
+// Span attributes
+const (
+	BackendKey   = attribute.Key("storage.backend")
+	BucketKey    = attribute.Key("storage.bucket")
+	KeyKey       = attribute.Key("storage.key")
+	SizeKey      = attribute.Key("storage.size")
+	ErrorCodeKey = attribute.Key("storage.error_code")
+	RequestIDKey = attribute.Key("storage.request_id")
+)
+
+// Options 链路追踪选项
+type Options struct {
+	TracerProvider trace.TracerProvider // 默认为 otel.GetTracerProvider()
+	SpanNamePrefix string               // span 名称前缀，默认 "storage."，例如 "storage.GetObject"
+}
+
+func (opt *Options) GetTracerProvider() trace.TracerProvider {
+	if opt.TracerProvider == nil {
+		return otel.GetTracerProvider()
+	}
+	return opt.TracerProvider
+}
+
+func (opt *Options) GetSpanNamePrefix() string {
+	if opt.SpanNamePrefix == "" {
+		return "storage."
+	}
+	return opt.SpanNamePrefix
+}
+
+// New returns storage whose calls create spans. The spans are children of the span in the
+// context bound with middleware.WithContext:
+//
+//	err := middleware.WithContext(storage, ctx).PutObject(key, reader)
+//
+// The inner calls of composite operations, such as CopyDir, are children of their span.
+// A nil options uses the defaults.
+func New(storage common.Storage, options *Options) *middleware.Storage {
+	return middleware.New(storage, NewInterceptor(options))
+}
+
+// NewInterceptor returns the interceptor of New. It replaces call.Context with the context of
+// its span, so place it before the interceptors whose spans or logs should belong to the call.
+// Placed after retry, every attempt gets its own span, the attempts being siblings.
+func NewInterceptor(options *Options) middleware.Interceptor {
+	if options == nil {
+		options = &Options{}
+	}
+	t := &tracer{
+		tracer: options.GetTracerProvider().Tracer(instrumentationName),
+		prefix: options.GetSpanNamePrefix(),
+	}
+	return t.intercept
+}
+
+type tracer struct {
+	tracer trace.Tracer
+	prefix string
+}
+
+func (t *tracer) intercept(call *middleware.Call, next middleware.Next) common.ObjectStorageError {
+	attrs := []attribute.KeyValue{
+		BackendKey.String(string(call.Location.Type)),
+		BucketKey.String(call.Bucket),
+	}
+	if call.Key != "" {
+		attrs = append(attrs, KeyKey.String(call.Key))
+	}
+
+	ctx, span := t.tracer.Start(call.Context, t.prefix+string(call.Operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	// restore the parent so that the spans of retried attempts are siblings
+	parent := call.Context
+	call.Context = ctx
+	se := next()
+	call.Context = parent
+
+	if call.Size >= 0 {
+		span.SetAttributes(SizeKey.Int64(call.Size))
+	}
+	if se != nil {
+		span.SetAttributes(ErrorCodeKey.String(se.GetCode()))
+		if se.GetRequestID() != "" {
+			span.SetAttributes(RequestIDKey.String(se.GetRequestID()))
+		}
+		span.RecordError(se)
+		span.SetStatus(codes.Error, se.GetMessage())
+	}
+	return se
+}
@@ -9,36 +9,51 @@ import (
 var ErrorCodeMap = map[string]string{
 	"NoSuchKey":               common.ErrCodeNoSuchKey,
 	"NoSuchBucket":            common.ErrCodeNoSuchBucket,
+	"NoSuchVersion":           common.ErrCodeNoSuchVersion,
 	"RequestTimeout":          common.ErrCodeRequestTimeout,
+	"SlowDown":                common.ErrCodeSlowDown,
 	"BucketNotFound":          common.ErrCodeNoSuchBucket,
 	"502 Bad Gateway":         common.ErrCodeBadGateway,
 	"InvalidAccessKeyId":      common.ErrCodeInvalidAccessKeyID,
 	"SignatureDoesNotMatch":   common.ErrCodeInvalidAccessKeySecret,
+	"AccessDenied":            common.ErrCodeAccessDenied,
 	"BucketAlreadyOwnedByYou": common.ErrCodeBucketAlreadyExists,
 	"XMinioInvalidObjectName": common.ErrCodeInvalidObjectName,
+	"InvalidTag":              common.ErrCodeInvalidTag,
+	"InvalidArgument":         common.ErrCodeInvalidArgument,
+	"EntityTooLarge":          common.ErrCodeEntityTooLarge,
+	"PreconditionFailed":      common.ErrCodePreconditionFailed,
+	"InvalidRange":            common.ErrCodeInvalidRange,
+	"SlowDownRead":            common.ErrCodeSlowDown,
+	"SlowDownWrite":           common.ErrCodeSlowDown,
+	"Throttled":               common.ErrCodeSlowDown,
+	"InternalError":           common.ErrCodeInternalError,
+	"NotImplemented":          common.ErrCodeNotImplemented,
+	"InvalidBucketName":       common.ErrCodeInvalidBucketName,
+	"BucketNotEmpty":          common.ErrCodeBucketNotEmpty,
+	"ServiceUnavailable":      common.ErrCodeServiceUnavailable,
+	"MethodNotAllowed":        common.ErrCodeMethodNotAllowed,
+
+	// MinIO specific codes
+	"XMinioAdminBucketQuotaExceeded": common.ErrCodeQuotaExceeded,
+	"XMinioServerNotInitialized":     common.ErrCodeServiceUnavailable,
 }
 
+// NoSuchHostErrorProcessor converts unknown hosts.
+//
+// Deprecated: network errors, including unknown hosts, are converted by
+// common.NetworkErrorProcessor, which HandleError already uses. It now delegates to it.
 type NoSuchHostErrorProcessor struct {
-	*common.BaseErrorProcessor
+	*common.NetworkErrorProcessor
 }
 
+// Deprecated: see NoSuchHostErrorProcessor.
 func NewNoSuchHostErrorProcessor() *NoSuchHostErrorProcessor {
 	return &NoSuchHostErrorProcessor{
-		&common.BaseErrorProcessor{},
+		common.NewNetworkErrorProcessor(common.MINIO),
 	}
 }
 
-func (p *NoSuchHostErrorProcessor) Match(err error) bool {
-	return err.Error() == "no such host"
-}
-
-func (p *NoSuchHostErrorProcessor) Process(err error) common.ObjectStorageError {
-	if p.Match(err) {
-		return common.NewStorageError(common.OSS, common.ErrCodeNoSuchBucket, err.Error(), err)
-	}
-	return p.ProcessNext(err)
-}
-
 type AccessDeniedErrorProcessor struct {
 	*common.BaseErrorProcessor
 }
@@ -55,7 +70,7 @@ func (p *AccessDeniedErrorProcessor) Match(err error) bool {
 
 func (p *AccessDeniedErrorProcessor) Process(err error) common.ObjectStorageError {
 	if p.Match(err) {
-		return common.NewStorageError(common.OSS, common.ErrCodeAccessDenied, err.Error(), err)
+		return common.NewStorageError(common.MINIO, common.ErrCodeAccessDenied, err.Error(), err)
 	}
 	return p.ProcessNext(err)
 }
@@ -87,7 +102,8 @@ func (p *DefaultErrorProcessor) Process(e error) common.ObjectStorageError {
 		minioError, _ := e.(minio.ErrorResponse)
 		code := p.getCode(minioError.Code)
 		message := minioError.Message
-		return common.NewStorageError(common.MINIO, code, message, e)
+		return common.NewStorageError(common.MINIO, code, message, e).
+			WithResponse(minioError.StatusCode, minioError.RequestID, minioError.HostID)
 	}
 	return p.ProcessNext(e)
 }
@@ -0,0 +1,57 @@
+package minio
+
+import (
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+func TestDefaultErrorProcessorCodes(t *testing.T) {
+	tests := []struct {
+		nativeCode string
+		code       common.ErrorCode
+	}{
+		{"NoSuchKey", common.ErrCodeNoSuchKey},
+		{"NoSuchBucket", common.ErrCodeNoSuchBucket},
+		{"NoSuchVersion", common.ErrCodeNoSuchVersion},
+		{"RequestTimeout", common.ErrCodeRequestTimeout},
+		{"SlowDown", common.ErrCodeSlowDown},
+		{"SlowDownRead", common.ErrCodeSlowDown},
+		{"SlowDownWrite", common.ErrCodeSlowDown},
+		{"Throttled", common.ErrCodeSlowDown},
+		{"BucketNotFound", common.ErrCodeNoSuchBucket},
+		{"502 Bad Gateway", common.ErrCodeBadGateway},
+		{"InvalidAccessKeyId", common.ErrCodeInvalidAccessKeyID},
+		{"SignatureDoesNotMatch", common.ErrCodeInvalidAccessKeySecret},
+		{"AccessDenied", common.ErrCodeAccessDenied},
+		{"BucketAlreadyOwnedByYou", common.ErrCodeBucketAlreadyExists},
+		{"XMinioInvalidObjectName", common.ErrCodeInvalidObjectName},
+		{"InvalidTag", common.ErrCodeInvalidTag},
+		{"InvalidArgument", common.ErrCodeInvalidArgument},
+		{"EntityTooLarge", common.ErrCodeEntityTooLarge},
+		{"PreconditionFailed", common.ErrCodePreconditionFailed},
+		{"InvalidRange", common.ErrCodeInvalidRange},
+		{"InternalError", common.ErrCodeInternalError},
+		{"NotImplemented", common.ErrCodeNotImplemented},
+		{"InvalidBucketName", common.ErrCodeInvalidBucketName},
+		{"BucketNotEmpty", common.ErrCodeBucketNotEmpty},
+		{"ServiceUnavailable", common.ErrCodeServiceUnavailable},
+		{"MethodNotAllowed", common.ErrCodeMethodNotAllowed},
+		{"XMinioAdminBucketQuotaExceeded", common.ErrCodeQuotaExceeded},
+		{"XMinioServerNotInitialized", common.ErrCodeServiceUnavailable},
+		{"SomethingNew", common.ErrCodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nativeCode, func(t *testing.T) {
+			se := NewDefaultErrorProcessor().Process(minio.ErrorResponse{Code: tt.nativeCode, RequestID: "req"})
+			if se.GetCode() != tt.code {
+				t.Errorf("code = %s, want %s", se.GetCode(), tt.code)
+			}
+			if se.GetProvider() != string(common.MINIO) || se.GetRequestID() != "req" {
+				t.Errorf("provider = %s, request id = %s", se.GetProvider(), se.GetRequestID())
+			}
+		})
+	}
+}
@@ -3,14 +3,17 @@ package minio
 import (
 	"context"
 	"io"
+	"strings"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
 
 	"github.com/xuelang-group/go-object-storage/common"
 )
 
 type MinioStorage struct {
+	endpoint     string
 	bucket       string
 	client       *minio.Client
 	errorConvert *minioErrorConvert
@@ -53,11 +56,20 @@ func NewMinioStorage(config *common.Config) (common.Storage, common.ObjectStorag
 
 	return &MinioStorage{
 		client:       client,
+		endpoint:     config.Endpoint,
 		bucket:       config.BucketName,
 		errorConvert: errConvert,
 	}, nil
 }
 
+func (m *MinioStorage) GetLocation() common.StorageLocation {
+	return common.StorageLocation{
+		Type:       common.MINIO,
+		Endpoint:   m.endpoint,
+		BucketName: m.bucket,
+	}
+}
+
 func (m *MinioStorage) CreateBucket(bucketName string) common.ObjectStorageError {
 	err := m.client.MakeBucket(context.Background(), bucketName, minio.MakeBucketOptions{})
 	return m.errorConvert.Convert(err)
@@ -93,6 +105,21 @@ func (m *MinioStorage) ObjectExist(objectKey string) (bool, common.ObjectStorage
 	return true, nil
 }
 
+func (m *MinioStorage) StatObject(objectKey string) (*common.ObjectInfo, common.ObjectStorageError) {
+	stat, err := m.client.StatObject(context.Background(), m.bucket, objectKey, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, m.errorConvert.Convert(err)
+	}
+	objInfo := common.NewObjectInfo(stat.Key, stat.Size, stat.LastModified)
+	objInfo.ETag = stat.ETag
+	objInfo.ContentType = stat.ContentType
+	objInfo.Metadata = make(map[string]string, len(stat.UserMetadata))
+	for key, value := range stat.UserMetadata {
+		objInfo.Metadata[strings.ToLower(key)] = value
+	}
+	return &objInfo, nil
+}
+
 func (m *MinioStorage) GetObject(objectKey string) (common.IObjectData, common.ObjectStorageError) {
 	_, err := m.client.StatObject(context.Background(), m.bucket, objectKey, minio.StatObjectOptions{})
 	if err != nil {
@@ -111,69 +138,172 @@ func (m *MinioStorage) FGetObject(objectKey, localFilePath string) common.Object
 	return m.errorConvert.Convert(err)
 }
 
+func (m *MinioStorage) FGetDir(prefix, localDir string, options *common.LocalDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.FGetDir(m, common.MINIO, prefix, localDir, options)
+}
+
+func (m *MinioStorage) FPutDir(localDir, prefix string, options *common.LocalDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.FPutDir(m, common.MINIO, localDir, prefix, options)
+}
+
 func (m *MinioStorage) FPutObject(localFilePath, objectKey string) common.ObjectStorageError {
+	return m.FPutObjectWithOptions(localFilePath, objectKey, nil)
+}
+
+func (m *MinioStorage) FPutObjectWithOptions(localFilePath, objectKey string, options *common.PutOptions) common.ObjectStorageError {
 	if !common.PathExists(localFilePath) {
 		return common.NewNoSuchFileError(common.MINIO, localFilePath)
 	}
-	_, err := m.client.FPutObject(context.Background(), m.bucket, objectKey, localFilePath, minio.PutObjectOptions{})
+	putOptions, se := getPutObjectOptions(options)
+	if se != nil {
+		return se
+	}
+	_, err := m.client.FPutObject(context.Background(), m.bucket, objectKey, localFilePath, putOptions)
 	return m.errorConvert.Convert(err)
 }
 
 func (m *MinioStorage) PutObject(objectKey string, reader io.Reader) common.ObjectStorageError {
-	_, err := m.client.PutObject(context.Background(), m.bucket, objectKey, reader, -1, minio.PutObjectOptions{})
+	return m.PutObjectWithOptions(objectKey, reader, nil)
+}
+
+func (m *MinioStorage) PutObjectWithOptions(objectKey string, reader io.Reader, options *common.PutOptions) common.ObjectStorageError {
+	putOptions, se := getPutObjectOptions(options)
+	if se != nil {
+		return se
+	}
+	size := int64(-1)
+	if options != nil && options.Size > 0 {
+		size = options.Size
+	}
+	_, err := m.client.PutObject(context.Background(), m.bucket, objectKey, reader, size, putOptions)
 	return m.errorConvert.Convert(err)
 }
 
 func (m *MinioStorage) ListObjects(opt common.ListOptions) ([]common.ObjectInfo, common.ObjectStorageError) {
+	if err := opt.Filter.Validate(); err != nil {
+		return nil, common.NewInvalidArgumentError(common.MINIO, err.Error())
+	}
+
 	var objects []common.ObjectInfo
+	var se common.ObjectStorageError
+
+	if opt.Recursive && opt.GetConcurrentNum() > 1 {
+		objects, se = common.ParallelListObjects(opt.GetPrefix(), opt, m.listLevel(opt.MaxKeys))
+	} else {
+		se = m.WalkObjects(opt, func(page []common.ObjectInfo) bool {
+			objects = append(objects, page...)
+			return true
+		})
+	}
+	if se != nil {
+		return nil, se
+	}
 
-	listOptions := minio.ListObjectsOptions{
-		Prefix:    opt.ObjectKeyPrefix,
-		MaxKeys:   opt.MaxKeys,
-		Recursive: opt.Recursive,
-	}
-
-	objectsCh := m.client.ListObjects(context.Background(), m.bucket, listOptions)
-
-	// Create a channel to receive objects from workers
-	resultCh := make(chan common.ObjectResult)
-
-	// Spawn worker goroutines to fetch object info concurrently
-	numWorkers := opt.GetConcurrentNum()
-
-	for i := 0; i < numWorkers; i++ {
-		go func() {
-			var objInfos []common.ObjectInfo
-			for object := range objectsCh {
-				if object.Err != nil {
-					resultCh <- common.ObjectResult{Objects: nil, E: object.Err} // 不能用 common.ObjectResult{nil, object.Err}，编译器会警告：composite literal uses unkeyed fields
-					return
-				}
-				objInfo := common.NewObjectInfo(object.Key, object.Size, object.LastModified)
-				if objInfo.IsListable(opt.ObjectKeyPrefix, opt.IncludeDirectories) {
-					objInfos = append(objInfos, objInfo)
-				}
+	common.SortObjects(objects, opt.SortBy, opt.SortOrder)
+
+	return objects, nil
+}
+
+// listLevel returns a common.ListLevelFunc requesting pages of maxKeys objects
+func (m *MinioStorage) listLevel(maxKeys int) common.ListLevelFunc {
+	return func(prefix string) ([]common.ObjectInfo, []string, common.ObjectStorageError) {
+		var objects []common.ObjectInfo
+		var prefixes []string
+
+		listOptions := minio.ListObjectsOptions{Prefix: prefix, MaxKeys: maxKeys}
+		for object := range m.client.ListObjects(context.Background(), m.bucket, listOptions) {
+			if object.Err != nil {
+				return nil, nil, m.errorConvert.Convert(object.Err)
 			}
-			resultCh <- common.ObjectResult{Objects: objInfos, E: nil}
-		}()
+			// keys ending with the delimiter are common prefixes, except the marker of prefix itself
+			if strings.HasSuffix(object.Key, "/") && object.Key != prefix {
+				prefixes = append(prefixes, object.Key)
+				continue
+			}
+			objInfo := common.NewObjectInfo(object.Key, object.Size, object.LastModified)
+			objInfo.ETag = object.ETag
+			objects = append(objects, objInfo)
+		}
+		return objects, prefixes, nil
+	}
+}
+
+func (m *MinioStorage) WalkObjects(opt common.ListOptions, fn common.ObjectPageFunc) common.ObjectStorageError {
+	if err := opt.Filter.Validate(); err != nil {
+		return common.NewInvalidArgumentError(common.MINIO, err.Error())
 	}
 
-	// Collect object info from workers
-	for i := 0; i < numWorkers; i++ {
-		objResult := <-resultCh
-		if objResult.E != nil {
-			return nil, m.errorConvert.Convert(objResult.E)
+	// cancel stops the listing goroutine of minio on early termination
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listOptions := minio.ListObjectsOptions{
+		Prefix:     opt.GetPrefix(),
+		MaxKeys:    opt.MaxKeys,
+		Recursive:  opt.Recursive,
+		StartAfter: opt.StartAfter,
+	}
+
+	dirFilter := common.NewDirectoryFilter(opt)
+	pageSize := opt.GetMaxKeys()
+	page := make([]common.ObjectInfo, 0, pageSize)
+	for object := range m.client.ListObjects(ctx, m.bucket, listOptions) {
+		if object.Err != nil {
+			return m.errorConvert.Convert(object.Err)
+		}
+		if !opt.Recursive && strings.HasSuffix(object.Key, "/") && object.Key != listOptions.Prefix {
+			// keys ending with the delimiter are common prefixes, except the marker of prefix itself
+			page = append(page, common.NewDirInfo(object.Key))
+		} else {
+			objInfo := common.NewObjectInfo(object.Key, object.Size, object.LastModified)
+			objInfo.ETag = object.ETag
+			page = append(page, objInfo)
 		}
-		objects = append(objects, objResult.Objects...)
+		if len(page) >= pageSize {
+			if filtered := dirFilter.Apply(page); len(filtered) > 0 && !fn(filtered) {
+				return nil
+			}
+			page = make([]common.ObjectInfo, 0, pageSize)
+		}
+	}
+	if filtered := dirFilter.Apply(page); len(filtered) > 0 {
+		fn(filtered)
 	}
+	return nil
+}
 
-	if !opt.IncludeDirectories {
-		objects = common.RemoveDirObjects(objects)
+func (m *MinioStorage) ListPage(opt common.ListOptions, pageToken string) ([]common.ObjectInfo, string, common.ObjectStorageError) {
+	if err := opt.Filter.Validate(); err != nil {
+		return nil, "", common.NewInvalidArgumentError(common.MINIO, err.Error())
+	}
+	continuationToken, se := common.DecodePageToken(common.MINIO, pageToken)
+	if se != nil {
+		return nil, "", se
 	}
 
-	common.SortObjects(objects, opt.SortBy, opt.SortOrder)
+	core := minio.Core{Client: m.client}
+	lsRes, err := core.ListObjectsV2(m.bucket, opt.GetPagePrefix(), opt.StartAfter, continuationToken, opt.GetPageDelimiter(), opt.GetMaxKeys())
+	if err != nil {
+		return nil, "", m.errorConvert.Convert(err)
+	}
 
-	return objects, nil
+	page := make([]common.ObjectInfo, 0, len(lsRes.Contents)+len(lsRes.CommonPrefixes))
+	for _, object := range lsRes.Contents {
+		objInfo := common.NewObjectInfo(object.Key, object.Size, object.LastModified)
+		objInfo.ETag = strings.Trim(object.ETag, `"`)
+		page = append(page, objInfo)
+	}
+	for _, dir := range lsRes.CommonPrefixes {
+		page = append(page, common.ObjectInfo{IsDir: true, Name: dir.Prefix})
+	}
+
+	nextToken := ""
+	if lsRes.IsTruncated {
+		nextToken = common.EncodePageToken(common.MINIO, lsRes.NextContinuationToken)
+	}
+	objects := common.FilterListPage(opt, page)
+	common.SortObjects(objects, common.SortByName, common.Ascending)
+	return objects, nextToken, nil
 }
 
 func (m *MinioStorage) DeleteObject(objectKey string) common.ObjectStorageError {
@@ -188,7 +318,43 @@ func (m *MinioStorage) DeleteObject(objectKey string) common.ObjectStorageError
 	return m.errorConvert.Convert(err)
 }
 
+func (m *MinioStorage) DeleteObjects(objectKeys []string) (*common.DeleteObjectsResult, common.ObjectStorageError) {
+	result := &common.DeleteObjectsResult{}
+
+	for _, chunk := range common.ChunkKeys(objectKeys, common.MaxDeleteObjectsBatch) {
+		objectsCh := make(chan minio.ObjectInfo, len(chunk))
+		for _, objectKey := range chunk {
+			objectsCh <- minio.ObjectInfo{Key: objectKey}
+		}
+		close(objectsCh)
+
+		// minio may report the same key twice when it falls back to a single delete
+		reported := make(map[string]bool, len(chunk))
+		for removeResult := range m.client.RemoveObjectsWithResult(context.Background(), m.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+			if reported[removeResult.ObjectName] {
+				continue
+			}
+			reported[removeResult.ObjectName] = true
+			if removeResult.Err != nil {
+				result.AddFailed(removeResult.ObjectName, m.errorConvert.Convert(removeResult.Err))
+			} else {
+				result.AddDeleted(removeResult.ObjectName)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (m *MinioStorage) DeletePrefix(prefix string, options *common.DeletePrefixOptions) (*common.DeleteObjectsResult, common.ObjectStorageError) {
+	return common.DeletePrefix(m, common.MINIO, prefix, options)
+}
+
 func (m *MinioStorage) CopyObject(srcObjectKey, destObjectKey string, options *common.CopyOptions) common.ObjectStorageError {
+	return m.CopyObjectFromBucket(m.bucket, srcObjectKey, destObjectKey, options)
+}
+
+func (m *MinioStorage) CopyObjectFromBucket(srcBucketName, srcObjectKey, destObjectKey string, options *common.CopyOptions) common.ObjectStorageError {
 	invalidObjectKey := common.FindFirstInvalidObject(srcObjectKey, destObjectKey)
 
 	if invalidObjectKey != "" {
@@ -210,7 +376,7 @@ func (m *MinioStorage) CopyObject(srcObjectKey, destObjectKey string, options *c
 	}
 
 	src := minio.CopySrcOptions{
-		Bucket: m.bucket,
+		Bucket: srcBucketName,
 		Object: srcObjectKey,
 	}
 
@@ -239,3 +405,167 @@ func (m *MinioStorage) MoveObject(srcObjectKey, destObjectKey string, options *c
 	}
 	return nil
 }
+
+func (m *MinioStorage) CopyDir(srcDirPath, destDirPath string, options *common.CopyDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.CopyDir(m, common.MINIO, srcDirPath, destDirPath, options)
+}
+
+func (m *MinioStorage) MoveDir(srcDirPath, destDirPath string, options *common.MoveDirOptions) (*common.DirTransferResult, common.ObjectStorageError) {
+	return common.MoveDir(m, common.MINIO, srcDirPath, destDirPath, options)
+}
+
+func (m *MinioStorage) MkDir(dirPath string) common.ObjectStorageError {
+	return common.MkDir(m, common.MINIO, dirPath)
+}
+
+func (m *MinioStorage) IsDir(dirPath string) (bool, common.ObjectStorageError) {
+	return common.IsDir(m, dirPath)
+}
+
+func (m *MinioStorage) RemoveDir(dirPath string, recursive bool) common.ObjectStorageError {
+	return common.RemoveDir(m, common.MINIO, dirPath, recursive)
+}
+
+func (m *MinioStorage) Sync(localDir, prefix string, options *common.SyncOptions) (*common.SyncResult, common.ObjectStorageError) {
+	return common.Sync(m, common.MINIO, localDir, prefix, options)
+}
+
+func (m *MinioStorage) GetObjectTags(objectKey string) (map[string]string, common.ObjectStorageError) {
+	objectTags, err := m.client.GetObjectTagging(context.Background(), m.bucket, objectKey, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return nil, m.errorConvert.Convert(err)
+	}
+	return objectTags.ToMap(), nil
+}
+
+func (m *MinioStorage) PutObjectTags(objectKey string, tags map[string]string) common.ObjectStorageError {
+	objectTags, se := toObjectTags(tags)
+	if se != nil {
+		return se
+	}
+	err := m.client.PutObjectTagging(context.Background(), m.bucket, objectKey, objectTags, minio.PutObjectTaggingOptions{})
+	return m.errorConvert.Convert(err)
+}
+
+func (m *MinioStorage) DeleteObjectTags(objectKey string) common.ObjectStorageError {
+	err := m.client.RemoveObjectTagging(context.Background(), m.bucket, objectKey, minio.RemoveObjectTaggingOptions{})
+	return m.errorConvert.Convert(err)
+}
+
+func (m *MinioStorage) EnableVersioning(bucketName string) common.ObjectStorageError {
+	err := m.client.EnableVersioning(context.Background(), bucketName)
+	return m.errorConvert.Convert(err)
+}
+
+func (m *MinioStorage) SuspendVersioning(bucketName string) common.ObjectStorageError {
+	err := m.client.SuspendVersioning(context.Background(), bucketName)
+	return m.errorConvert.Convert(err)
+}
+
+func (m *MinioStorage) GetVersioningStatus(bucketName string) (common.VersioningStatus, common.ObjectStorageError) {
+	config, err := m.client.GetBucketVersioning(context.Background(), bucketName)
+	if err != nil {
+		return common.VersioningUnversioned, m.errorConvert.Convert(err)
+	}
+	return common.VersioningStatus(config.Status), nil
+}
+
+func (m *MinioStorage) GetBucketLifecycle(bucketName string) (*common.LifecycleConfiguration, common.ObjectStorageError) {
+	lifecycleConfig, err := m.client.GetBucketLifecycle(context.Background(), bucketName)
+	if err != nil {
+		if isLifecycleNotFoundError(err) {
+			return &common.LifecycleConfiguration{}, nil
+		}
+		return nil, m.errorConvert.Convert(err)
+	}
+	return fromLifecycleConfiguration(lifecycleConfig), nil
+}
+
+func (m *MinioStorage) PutBucketLifecycle(bucketName string, config *common.LifecycleConfiguration) common.ObjectStorageError {
+	if config.IsEmpty() {
+		return m.DeleteBucketLifecycle(bucketName)
+	}
+	lifecycleConfig, se := toLifecycleConfiguration(config)
+	if se != nil {
+		return se
+	}
+	err := m.client.SetBucketLifecycle(context.Background(), bucketName, lifecycleConfig)
+	return m.errorConvert.Convert(err)
+}
+
+func (m *MinioStorage) DeleteBucketLifecycle(bucketName string) common.ObjectStorageError {
+	// an empty configuration removes the lifecycle rules
+	err := m.client.SetBucketLifecycle(context.Background(), bucketName, lifecycle.NewConfiguration())
+	return m.errorConvert.Convert(err)
+}
+
+func (m *MinioStorage) ListObjectVersions(opt common.ListOptions) ([]common.ObjectInfo, common.ObjectStorageError) {
+	var objects []common.ObjectInfo
+
+	listOptions := minio.ListObjectsOptions{
+		WithVersions: true,
+		Prefix:       opt.ObjectKeyPrefix,
+		MaxKeys:      opt.MaxKeys,
+		Recursive:    opt.Recursive,
+	}
+
+	for object := range m.client.ListObjects(context.Background(), m.bucket, listOptions) {
+		if object.Err != nil {
+			return nil, m.errorConvert.Convert(object.Err)
+		}
+		objInfo := common.NewObjectVersionInfo(object.Key, object.Size, object.LastModified, object.VersionID, object.IsLatest, object.IsDeleteMarker)
+		if !opt.Recursive && strings.HasSuffix(object.Key, "/") && object.Key != opt.ObjectKeyPrefix {
+			objInfo = common.NewDirInfo(object.Key)
+		}
+		if objInfo.IsVersionListable(opt.ObjectKeyPrefix, opt.IncludeDirectories) {
+			objects = append(objects, objInfo)
+		}
+	}
+
+	common.SortObjects(objects, opt.SortBy, opt.SortOrder)
+
+	return objects, nil
+}
+
+func (m *MinioStorage) GetObjectVersion(objectKey, versionID string) (common.IObjectData, common.ObjectStorageError) {
+	if versionID == "" {
+		return nil, common.NewObjectVersionNotFoundError(common.MINIO, objectKey, versionID)
+	}
+	_, err := m.client.StatObject(context.Background(), m.bucket, objectKey, minio.StatObjectOptions{VersionID: versionID})
+	if err != nil {
+		return nil, m.errorConvert.Convert(err)
+	}
+	objReader, err := m.client.GetObject(context.Background(), m.bucket, objectKey, minio.GetObjectOptions{VersionID: versionID})
+	if err != nil {
+		return nil, m.errorConvert.Convert(err)
+	}
+	return common.NewObjectData(objReader), nil
+}
+
+func (m *MinioStorage) DeleteObjectVersion(objectKey, versionID string) common.ObjectStorageError {
+	if versionID == "" {
+		return common.NewObjectVersionNotFoundError(common.MINIO, objectKey, versionID)
+	}
+	err := m.client.RemoveObject(context.Background(), m.bucket, objectKey, minio.RemoveObjectOptions{VersionID: versionID})
+	return m.errorConvert.Convert(err)
+}
+
+func (m *MinioStorage) RestoreObjectVersion(objectKey, versionID string) common.ObjectStorageError {
+	if versionID == "" {
+		return common.NewObjectVersionNotFoundError(common.MINIO, objectKey, versionID)
+	}
+
+	src := minio.CopySrcOptions{
+		Bucket:    m.bucket,
+		Object:    objectKey,
+		VersionID: versionID,
+	}
+
+	dst := minio.CopyDestOptions{
+		Bucket: m.bucket,
+		Object: objectKey,
+	}
+	_, err := m.client.CopyObject(context.Background(), dst, src)
+
+	return m.errorConvert.Convert(err)
+}
@@ -0,0 +1,110 @@
+package minio
+
+import (
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+const (
+	lifecycleStatusEnabled  = "Enabled"
+	lifecycleStatusDisabled = "Disabled"
+)
+
+func toLifecycleConfiguration(config *common.LifecycleConfiguration) (*lifecycle.Configuration, common.ObjectStorageError) {
+	if err := config.Validate(); err != nil {
+		return nil, common.NewInvalidArgumentError(common.MINIO, err.Error())
+	}
+
+	lifecycleConfig := lifecycle.NewConfiguration()
+	for _, rule := range config.Rules {
+		if len(rule.Transitions) > 1 {
+			return nil, common.NewInvalidArgumentError(common.MINIO, "only one transition is supported per lifecycle rule: "+rule.ID)
+		}
+
+		minioRule := lifecycle.Rule{
+			ID:         rule.ID,
+			Status:     lifecycleStatusDisabled,
+			RuleFilter: lifecycle.Filter{Prefix: rule.Prefix},
+		}
+		if rule.Enabled {
+			minioRule.Status = lifecycleStatusEnabled
+		}
+		if rule.Expiration != nil {
+			minioRule.Expiration = lifecycle.Expiration{
+				Days: lifecycle.ExpirationDays(rule.Expiration.Days),
+				Date: lifecycle.ExpirationDate{Time: rule.Expiration.Date},
+			}
+		}
+		if rule.NoncurrentVersionExpiration != nil {
+			minioRule.NoncurrentVersionExpiration = lifecycle.NoncurrentVersionExpiration{
+				NoncurrentDays: lifecycle.ExpirationDays(rule.NoncurrentVersionExpiration.NoncurrentDays),
+			}
+		}
+		if len(rule.Transitions) == 1 {
+			transition := rule.Transitions[0]
+			minioRule.Transition = lifecycle.Transition{
+				Days:         lifecycle.ExpirationDays(transition.Days),
+				Date:         lifecycle.ExpirationDate{Time: transition.Date},
+				StorageClass: transition.StorageClass,
+			}
+		}
+		if rule.AbortIncompleteMultipartUpload != nil {
+			minioRule.AbortIncompleteMultipartUpload = lifecycle.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: lifecycle.ExpirationDays(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation),
+			}
+		}
+		lifecycleConfig.Rules = append(lifecycleConfig.Rules, minioRule)
+	}
+	return lifecycleConfig, nil
+}
+
+func fromLifecycleConfiguration(lifecycleConfig *lifecycle.Configuration) *common.LifecycleConfiguration {
+	config := &common.LifecycleConfiguration{}
+	if lifecycleConfig == nil {
+		return config
+	}
+
+	for _, minioRule := range lifecycleConfig.Rules {
+		rule := common.LifecycleRule{
+			ID:      minioRule.ID,
+			Prefix:  getLifecycleRulePrefix(minioRule),
+			Enabled: minioRule.Status == lifecycleStatusEnabled,
+		}
+		if !minioRule.Expiration.IsDaysNull() || !minioRule.Expiration.IsDateNull() {
+			rule.Expiration = &common.LifecycleExpiration{
+				Days: int(minioRule.Expiration.Days),
+				Date: minioRule.Expiration.Date.Time,
+			}
+		}
+		if minioRule.NoncurrentVersionExpiration.NoncurrentDays > 0 {
+			rule.NoncurrentVersionExpiration = &common.NoncurrentVersionExpiration{
+				NoncurrentDays: int(minioRule.NoncurrentVersionExpiration.NoncurrentDays),
+			}
+		}
+		if !minioRule.Transition.IsNull() {
+			rule.Transitions = append(rule.Transitions, common.LifecycleTransition{
+				Days:         int(minioRule.Transition.Days),
+				Date:         minioRule.Transition.Date.Time,
+				StorageClass: minioRule.Transition.StorageClass,
+			})
+		}
+		if minioRule.AbortIncompleteMultipartUpload.DaysAfterInitiation > 0 {
+			rule.AbortIncompleteMultipartUpload = &common.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: int(minioRule.AbortIncompleteMultipartUpload.DaysAfterInitiation),
+			}
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+	return config
+}
+
+func getLifecycleRulePrefix(rule lifecycle.Rule) string {
+	if rule.RuleFilter.Prefix != "" {
+		return rule.RuleFilter.Prefix
+	}
+	if rule.RuleFilter.And.Prefix != "" {
+		return rule.RuleFilter.And.Prefix
+	}
+	return rule.Prefix
+}
@@ -0,0 +1,77 @@
+package minio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+const listVersionsResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ListVersionsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>bucket</Name>
+  <Prefix>studio/100003/parameter.js</Prefix>
+  <IsTruncated>false</IsTruncated>
+  <Version>
+    <Key>studio/100003/parameter.js</Key>
+    <VersionId>v2</VersionId>
+    <IsLatest>true</IsLatest>
+    <LastModified>2022-01-02T00:00:00.000Z</LastModified>
+    <Size>20</Size>
+  </Version>
+  <Version>
+    <Key>studio/100003/parameter.js</Key>
+    <VersionId>v1</VersionId>
+    <IsLatest>false</IsLatest>
+    <LastModified>2022-01-01T00:00:00.000Z</LastModified>
+    <Size>10</Size>
+  </Version>
+  <Version>
+    <Key>studio/100003/parameter.json</Key>
+    <VersionId>v3</VersionId>
+    <IsLatest>true</IsLatest>
+    <LastModified>2022-01-01T00:00:00.000Z</LastModified>
+    <Size>5</Size>
+  </Version>
+</ListVersionsResult>`
+
+func TestListObjectVersionsByKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		if _, ok := r.URL.Query()["location"]; ok {
+			w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">us-east-1</LocationConstraint>`))
+			return
+		}
+		if _, ok := r.URL.Query()["versions"]; !ok {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		w.Write([]byte(listVersionsResponse))
+	}))
+	defer server.Close()
+
+	storage, se := NewMinioStorage(&common.Config{
+		Endpoint:        server.URL,
+		AccessKeyID:     "access",
+		AccessKeySecret: "secret",
+		BucketName:      "bucket",
+	})
+	if se != nil {
+		t.Fatal(se)
+	}
+
+	versions, se := storage.ListObjectVersions(common.ListOptions{ObjectKeyPrefix: "studio/100003/parameter.js"})
+	if se != nil {
+		t.Fatal(se)
+	}
+	var ids []string
+	for _, version := range versions {
+		if version.Name == "studio/100003/parameter.js" {
+			ids = append(ids, version.VersionID)
+		}
+	}
+	if len(ids) != 2 {
+		t.Fatalf("versions of the exact key = %v, want v1 and v2", ids)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
 
 	"github.com/xuelang-group/go-object-storage/common"
 )
@@ -26,3 +27,35 @@ func isObjectNotFoundError(err error) bool {
 	}
 	return false
 }
+
+func isLifecycleNotFoundError(err error) bool {
+	if errResponse, ok := err.(minio.ErrorResponse); ok && errResponse.Code == "NoSuchLifecycleConfiguration" {
+		return true
+	}
+	return false
+}
+
+// toObjectTags validates the tags against the object tagging limits.
+func toObjectTags(tagMap map[string]string) (*tags.Tags, common.ObjectStorageError) {
+	objectTags, err := tags.MapToObjectTags(tagMap)
+	if err != nil {
+		return nil, common.NewInvalidTagError(common.MINIO, err.Error())
+	}
+	return objectTags, nil
+}
+
+func getPutObjectOptions(options *common.PutOptions) (minio.PutObjectOptions, common.ObjectStorageError) {
+	putOptions := minio.PutObjectOptions{}
+	if options == nil {
+		return putOptions, nil
+	}
+	if len(options.Tags) > 0 {
+		if _, se := toObjectTags(options.Tags); se != nil {
+			return putOptions, se
+		}
+		putOptions.UserTags = options.Tags
+	}
+	putOptions.UserMetadata = options.Metadata
+	putOptions.ContentType = options.ContentType
+	return putOptions, nil
+}
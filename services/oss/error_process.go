@@ -1,8 +1,6 @@
 package oss
 
 import (
-	"strings"
-
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 
 	"github.com/xuelang-group/go-object-storage/common"
@@ -11,34 +9,44 @@ import (
 var ErrorCodeMap = map[string]common.ErrorCode{
 	"NoSuchKey":             common.ErrCodeNoSuchKey,
 	"NoSuchBucket":          common.ErrCodeNoSuchBucket,
+	"NoSuchVersion":         common.ErrCodeNoSuchVersion,
 	"AccessDenied":          common.ErrCodeAccessDenied,
 	"BucketNotFound":        common.ErrCodeNoSuchBucket,
 	"RequestTimeout":        common.ErrCodeRequestTimeout,
+	"SlowDown":              common.ErrCodeSlowDown,
 	"InvalidObjectName":     common.ErrCodeInvalidObjectName,
 	"InvalidAccessKeyId":    common.ErrCodeInvalidAccessKeyID,
 	"BucketAlreadyExists":   common.ErrCodeBucketAlreadyExists,
 	"SignatureDoesNotMatch": common.ErrCodeInvalidAccessKeySecret,
+	"InvalidTag":            common.ErrCodeInvalidTag,
+	"InvalidArgument":       common.ErrCodeInvalidArgument,
+	"EntityTooLarge":        common.ErrCodeEntityTooLarge,
+	"PreconditionFailed":    common.ErrCodePreconditionFailed,
+	"InvalidRange":          common.ErrCodeInvalidRange,
+	"QpsLimitExceeded":      common.ErrCodeSlowDown,
+	"Throttled":             common.ErrCodeSlowDown,
+	"QuotaExceeded":         common.ErrCodeQuotaExceeded,
+	"InternalError":         common.ErrCodeInternalError,
+	"NotImplemented":        common.ErrCodeNotImplemented,
+	"InvalidBucketName":     common.ErrCodeInvalidBucketName,
+	"BucketNotEmpty":        common.ErrCodeBucketNotEmpty,
+	"ServiceUnavailable":    common.ErrCodeServiceUnavailable,
+	"MethodNotAllowed":      common.ErrCodeMethodNotAllowed,
 }
 
+// NoSuchHostErrorProcessor converts unknown hosts.
+//
+// Deprecated: network errors, including unknown hosts, are converted by
+// common.NetworkErrorProcessor, which HandleError already uses. It now delegates to it.
 type NoSuchHostErrorProcessor struct {
-	*common.BaseErrorProcessor
+	*common.NetworkErrorProcessor
 }
 
+// Deprecated: see NoSuchHostErrorProcessor.
 func NewNoSuchHostErrorProcessor() *NoSuchHostErrorProcessor {
 	return &NoSuchHostErrorProcessor{
-		&common.BaseErrorProcessor{},
-	}
-}
-
-func (p *NoSuchHostErrorProcessor) Match(err error) bool {
-	return strings.Contains(err.Error(), "no such host")
-}
-
-func (p *NoSuchHostErrorProcessor) Process(err error) common.ObjectStorageError {
-	if p.Match(err) {
-		return common.NewStorageError(common.OSS, common.ErrCodeBadGateway, err.Error(), err)
+		common.NewNetworkErrorProcessor(common.OSS),
 	}
-	return p.ProcessNext(err)
 }
 
 type AccessDeniedErrorProcessor struct {
@@ -89,7 +97,8 @@ func (p *DefaultErrorProcessor) Process(e error) common.ObjectStorageError {
 		ossError, _ := e.(oss.ServiceError)
 		code := p.getCode(ossError.Code)
 		message := ossError.Message
-		return common.NewStorageError(common.OSS, code, message, e)
+		return common.NewStorageError(common.OSS, code, message, e).
+			WithResponse(ossError.StatusCode, ossError.RequestID, ossError.HostID)
 	}
 	return p.ProcessNext(e)
 }
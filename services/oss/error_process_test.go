@@ -0,0 +1,54 @@
+package oss
+
+import (
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+func TestDefaultErrorProcessorCodes(t *testing.T) {
+	tests := []struct {
+		nativeCode string
+		code       common.ErrorCode
+	}{
+		{"NoSuchKey", common.ErrCodeNoSuchKey},
+		{"NoSuchBucket", common.ErrCodeNoSuchBucket},
+		{"NoSuchVersion", common.ErrCodeNoSuchVersion},
+		{"AccessDenied", common.ErrCodeAccessDenied},
+		{"BucketNotFound", common.ErrCodeNoSuchBucket},
+		{"RequestTimeout", common.ErrCodeRequestTimeout},
+		{"SlowDown", common.ErrCodeSlowDown},
+		{"QpsLimitExceeded", common.ErrCodeSlowDown},
+		{"Throttled", common.ErrCodeSlowDown},
+		{"InvalidObjectName", common.ErrCodeInvalidObjectName},
+		{"InvalidAccessKeyId", common.ErrCodeInvalidAccessKeyID},
+		{"BucketAlreadyExists", common.ErrCodeBucketAlreadyExists},
+		{"SignatureDoesNotMatch", common.ErrCodeInvalidAccessKeySecret},
+		{"InvalidTag", common.ErrCodeInvalidTag},
+		{"InvalidArgument", common.ErrCodeInvalidArgument},
+		{"EntityTooLarge", common.ErrCodeEntityTooLarge},
+		{"PreconditionFailed", common.ErrCodePreconditionFailed},
+		{"InvalidRange", common.ErrCodeInvalidRange},
+		{"QuotaExceeded", common.ErrCodeQuotaExceeded},
+		{"InternalError", common.ErrCodeInternalError},
+		{"NotImplemented", common.ErrCodeNotImplemented},
+		{"InvalidBucketName", common.ErrCodeInvalidBucketName},
+		{"BucketNotEmpty", common.ErrCodeBucketNotEmpty},
+		{"ServiceUnavailable", common.ErrCodeServiceUnavailable},
+		{"MethodNotAllowed", common.ErrCodeMethodNotAllowed},
+		{"SomethingNew", common.ErrCodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nativeCode, func(t *testing.T) {
+			se := NewDefaultErrorProcessor().Process(oss.ServiceError{Code: tt.nativeCode, RequestID: "req"})
+			if se.GetCode() != tt.code {
+				t.Errorf("code = %s, want %s", se.GetCode(), tt.code)
+			}
+			if se.GetProvider() != string(common.OSS) || se.GetRequestID() != "req" {
+				t.Errorf("provider = %s, request id = %s", se.GetProvider(), se.GetRequestID())
+			}
+		})
+	}
+}
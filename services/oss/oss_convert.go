@@ -14,15 +14,26 @@ func (c *ossErrorConvert) Convert(err error) common.ObjectStorageError {
 	if e, ok := err.(common.ObjectStorageError); ok {
 		return e
 	}
-	return HandleError(err)
+	if se := HandleError(err); se != nil {
+		return se
+	}
+	// no processor matched, never report a failure as success
+	return common.NewStorageError(common.OSS, common.ErrCodeUnknown, err.Error(), err)
 }
 
-func HandleError(err error) common.ObjectStorageError {
-	defaultProcessor := NewDefaultErrorProcessor()
-	noSuchHostProcessor := NewNoSuchHostErrorProcessor()
-	accessDeniedProcessor := NewAccessDeniedErrorProcessor()
+// errorProcessors converts the errors of the SDK, see RegisterErrorProcessor.
+var errorProcessors = common.NewErrorProcessorRegistry(
+	NewDefaultErrorProcessor(),
+	common.NewNetworkErrorProcessor(common.OSS),
+	NewAccessDeniedErrorProcessor(),
+)
 
-	defaultProcessor.SetNext(noSuchHostProcessor)
-	noSuchHostProcessor.SetNext(accessDeniedProcessor)
-	return defaultProcessor.Process(err)
+// RegisterErrorProcessor adds a custom processor that is tried before the built-in ones.
+// Its Process method is only called with errors it matches.
+func RegisterErrorProcessor(processor common.ErrorProcessor) {
+	errorProcessors.Register(processor)
+}
+
+func HandleError(err error) common.ObjectStorageError {
+	return errorProcessors.Process(err)
 }
@@ -0,0 +1,122 @@
+package oss
+
+import (
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+const (
+	lifecycleStatusEnabled  = "Enabled"
+	lifecycleStatusDisabled = "Disabled"
+
+	// OSS 只接受 UTC 零点的日期
+	lifecycleDateFormat = "2006-01-02T00:00:00.000Z"
+)
+
+var lifecycleDateLayouts = []string{
+	"2006-01-02T15:04:05.000Z",
+	time.RFC3339,
+}
+
+func formatLifecycleDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.UTC().Format(lifecycleDateFormat)
+}
+
+func parseLifecycleDate(value string) time.Time {
+	for _, layout := range lifecycleDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
+func toLifecycleRules(config *common.LifecycleConfiguration) ([]oss.LifecycleRule, common.ObjectStorageError) {
+	if err := config.Validate(); err != nil {
+		return nil, common.NewInvalidArgumentError(common.OSS, err.Error())
+	}
+
+	rules := make([]oss.LifecycleRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		ossRule := oss.LifecycleRule{
+			ID:     rule.ID,
+			Prefix: rule.Prefix,
+			Status: lifecycleStatusDisabled,
+		}
+		if rule.Enabled {
+			ossRule.Status = lifecycleStatusEnabled
+		}
+		if rule.Expiration != nil {
+			ossRule.Expiration = &oss.LifecycleExpiration{
+				Days:              rule.Expiration.Days,
+				CreatedBeforeDate: formatLifecycleDate(rule.Expiration.Date),
+			}
+		}
+		if rule.NoncurrentVersionExpiration != nil {
+			ossRule.NonVersionExpiration = &oss.LifecycleVersionExpiration{
+				NoncurrentDays: rule.NoncurrentVersionExpiration.NoncurrentDays,
+			}
+		}
+		for _, transition := range rule.Transitions {
+			ossRule.Transitions = append(ossRule.Transitions, oss.LifecycleTransition{
+				Days:              transition.Days,
+				CreatedBeforeDate: formatLifecycleDate(transition.Date),
+				StorageClass:      oss.StorageClassType(transition.StorageClass),
+			})
+		}
+		if rule.AbortIncompleteMultipartUpload != nil {
+			ossRule.AbortMultipartUpload = &oss.LifecycleAbortMultipartUpload{
+				Days: rule.AbortIncompleteMultipartUpload.DaysAfterInitiation,
+			}
+		}
+		rules = append(rules, ossRule)
+	}
+	return rules, nil
+}
+
+func fromLifecycleRules(rules []oss.LifecycleRule) *common.LifecycleConfiguration {
+	config := &common.LifecycleConfiguration{}
+
+	for _, ossRule := range rules {
+		rule := common.LifecycleRule{
+			ID:      ossRule.ID,
+			Prefix:  ossRule.Prefix,
+			Enabled: ossRule.Status == lifecycleStatusEnabled,
+		}
+		if expiration := ossRule.Expiration; expiration != nil && (expiration.Days > 0 || expiration.CreatedBeforeDate != "" || expiration.Date != "") {
+			date := expiration.CreatedBeforeDate
+			if date == "" {
+				date = expiration.Date
+			}
+			rule.Expiration = &common.LifecycleExpiration{
+				Days: expiration.Days,
+				Date: parseLifecycleDate(date),
+			}
+		}
+		if ossRule.NonVersionExpiration != nil {
+			rule.NoncurrentVersionExpiration = &common.NoncurrentVersionExpiration{
+				NoncurrentDays: ossRule.NonVersionExpiration.NoncurrentDays,
+			}
+		}
+		for _, transition := range ossRule.Transitions {
+			rule.Transitions = append(rule.Transitions, common.LifecycleTransition{
+				Days:         transition.Days,
+				Date:         parseLifecycleDate(transition.CreatedBeforeDate),
+				StorageClass: string(transition.StorageClass),
+			})
+		}
+		if ossRule.AbortMultipartUpload != nil {
+			rule.AbortIncompleteMultipartUpload = &common.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: ossRule.AbortMultipartUpload.Days,
+			}
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+	return config
+}
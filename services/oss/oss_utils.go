@@ -1 +1,68 @@
 package oss
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/xuelang-group/go-object-storage/common"
+)
+
+func toTagging(tags map[string]string) oss.Tagging {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	// keep the request body stable
+	sort.Strings(keys)
+
+	tagging := oss.Tagging{Tags: make([]oss.Tag, 0, len(keys))}
+	for _, key := range keys {
+		tagging.Tags = append(tagging.Tags, oss.Tag{Key: key, Value: tags[key]})
+	}
+	return tagging
+}
+
+func getPutObjectOptions(options *common.PutOptions) []oss.Option {
+	var ossOptions []oss.Option
+	if options == nil {
+		return ossOptions
+	}
+	if len(options.Tags) > 0 {
+		ossOptions = append(ossOptions, oss.SetTagging(toTagging(options.Tags)))
+	}
+	for key, value := range options.Metadata {
+		ossOptions = append(ossOptions, oss.Meta(key, value))
+	}
+	if options.ContentType != "" {
+		ossOptions = append(ossOptions, oss.ContentType(options.ContentType))
+	}
+	return ossOptions
+}
+
+func toObjectInfo(objectKey string, header http.Header) common.ObjectInfo {
+	size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+	lastModified, _ := http.ParseTime(header.Get(oss.HTTPHeaderLastModified))
+
+	objInfo := common.NewObjectInfo(objectKey, size, lastModified)
+	objInfo.ETag = strings.Trim(header.Get(oss.HTTPHeaderEtag), `"`)
+	objInfo.ContentType = header.Get(oss.HTTPHeaderContentType)
+	objInfo.Metadata = make(map[string]string)
+	for key := range header {
+		if strings.HasPrefix(key, oss.HTTPHeaderOssMetaPrefix) {
+			metaKey := strings.ToLower(key[len(oss.HTTPHeaderOssMetaPrefix):])
+			objInfo.Metadata[metaKey] = header.Get(key)
+		}
+	}
+	return objInfo
+}
+
+func isLifecycleNotFoundError(err error) bool {
+	if serviceError, ok := err.(oss.ServiceError); ok && serviceError.Code == "NoSuchLifecycle" {
+		return true
+	}
+	return false
+}